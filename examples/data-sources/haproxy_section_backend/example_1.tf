@@ -0,0 +1,14 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_haproxy_section_backend" "example" {
+  server_id = 1
+  name      = "example-backend"
+}
+
+output "backend_servers" {
+  value = data.roxywi_haproxy_section_backend.example.backend_servers[*].server
+}
@@ -0,0 +1,13 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_haproxy_section_defaults" "example" {
+  server_id = 1
+}
+
+output "data" {
+  value = data.roxywi_haproxy_section_defaults.example
+}
@@ -0,0 +1,14 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_haproxy_section_frontend" "example" {
+  server_id = 1
+  name      = "example-frontend"
+}
+
+output "data" {
+  value = data.roxywi_haproxy_section_frontend.example
+}
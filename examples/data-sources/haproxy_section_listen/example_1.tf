@@ -0,0 +1,14 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_haproxy_section_listen" "example" {
+  server_id = 1
+  name      = "example-listen"
+}
+
+output "data" {
+  value = data.roxywi_haproxy_section_listen.example
+}
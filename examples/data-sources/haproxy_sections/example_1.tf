@@ -0,0 +1,25 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_haproxy_sections" "haproxy" {
+  server_id = 1
+}
+
+// ------------------------------------
+
+data "roxywi_haproxy_section_backend" "all" {
+  for_each = toset([
+    for section in data.roxywi_haproxy_sections.haproxy.sections : section.name
+    if section.type == "backend"
+  ])
+
+  server_id = 1
+  name      = each.value
+}
+
+output "backend_modes" {
+  value = { for name, backend in data.roxywi_haproxy_section_backend.all : name => backend.mode }
+}
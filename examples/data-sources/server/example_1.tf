@@ -0,0 +1,26 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_server" "example_hostname" {
+  hostname = "haproxy-1"
+}
+
+resource "roxywi_haproxy_section_backend" "example" {
+  name      = "example"
+  mode      = "http"
+  balance   = "roundrobin"
+  server_id = data.roxywi_server.example_hostname.id
+}
+
+// ------------------------------------
+
+data "roxywi_server" "example_ip" {
+  ip = "10.0.0.10"
+}
+
+output "data" {
+  value = data.roxywi_server.example_ip
+}
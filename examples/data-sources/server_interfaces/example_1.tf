@@ -0,0 +1,17 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_server" "haproxy" {
+  hostname = "haproxy-1"
+}
+
+data "roxywi_server_interfaces" "haproxy" {
+  server_id = data.roxywi_server.haproxy.id
+}
+
+output "ips" {
+  value = data.roxywi_server_interfaces.haproxy.ips
+}
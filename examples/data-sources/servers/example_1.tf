@@ -0,0 +1,15 @@
+provider "roxywi" {
+  base_url = "https://..."
+  login    = "test"
+  password = "testpass"
+}
+
+data "roxywi_servers" "example" {
+  group_id       = 1
+  enabled        = true
+  hostname_regex = "^haproxy-"
+}
+
+output "server_ids" {
+  value = data.roxywi_servers.example.servers[*].id
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"log"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	"terraform-provider-roxywi/roxywi"
@@ -21,4 +23,8 @@ func main() {
 		ProviderFunc: roxywi.Provider,
 	}
 	plugin.Serve(opts)
+
+	if err := roxywi.ShutdownTracing(context.Background()); err != nil {
+		log.Printf("[WARN] unable to export traces: %s", err)
+	}
 }
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// FsBackup copies the configuration of a server to a remote server over SSH.
+type FsBackup struct {
+	CredID      Int    `json:"cred_id"`
+	Description String `json:"description"`
+	RPath       String `json:"rpath"`
+	RServer     String `json:"rserver"`
+	ServerID    Int    `json:"server_id"`
+	Time        String `json:"time"`
+	Type        String `json:"type"`
+}
+
+// GitBackup pushes the configuration of a service to a Git repository.
+type GitBackup struct {
+	CredID      Int    `json:"cred_id"`
+	Description String `json:"description"`
+	Branch      String `json:"branch"`
+	Repo        String `json:"repo"`
+	ServerID    Int    `json:"server_id"`
+	ServiceID   Int    `json:"service_id"`
+	Time        String `json:"time"`
+}
+
+// S3Backup uploads the configuration of a server to an S3 bucket.
+type S3Backup struct {
+	S3Server    String `json:"s3_server"`
+	AccessKey   String `json:"access_key"`
+	SecretKey   String `json:"secret_key"`
+	Bucket      String `json:"bucket"`
+	Description String `json:"description"`
+	ServerID    Int    `json:"server_id"`
+	Time        String `json:"time"`
+}
+
+// backupPath returns the backups of the given kind, "fs", "git" or "s3", or
+// the backup with the optional ID.
+func backupPath(kind string, id ...interface{}) string {
+	return Path(append([]interface{}{"api", "server", "backup", kind}, id...)...)
+}
+
+// backupJobRef names the backup job of a server in the requests deleting FS
+// and Git backups.
+type backupJobRef struct {
+	ServerID Int `json:"server_id"`
+	CredID   Int `json:"cred_id"`
+}
+
+// s3BackupRef names the backup job of a server in the requests deleting S3
+// backups.
+type s3BackupRef struct {
+	Bucket   String `json:"bucket"`
+	ServerID Int    `json:"server_id"`
+}
+
+func (c *Client) GetFsBackup(ctx context.Context, id string) (*FsBackup, error) {
+	var backup FsBackup
+	err := c.get(ctx, backupPath("fs", id), &backup)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &backup, err
+}
+
+// CreateFsBackup creates backup and returns its ID.
+func (c *Client) CreateFsBackup(ctx context.Context, backup *FsBackup) (string, error) {
+	return c.create(ctx, backupPath("fs"), backup)
+}
+
+func (c *Client) UpdateFsBackup(ctx context.Context, id string, backup *FsBackup) error {
+	return c.call(ctx, http.MethodPut, backupPath("fs", id), backup, nil)
+}
+
+// DeleteFsBackup deletes the backup with the given ID of serverID, which
+// Roxy-WI connects to with credID to remove the backup job.
+func (c *Client) DeleteFsBackup(ctx context.Context, id string, serverID, credID int) error {
+	ref := &backupJobRef{ServerID: Int(serverID), CredID: Int(credID)}
+	return c.call(ctx, http.MethodDelete, backupPath("fs", id), ref, nil)
+}
+
+func (c *Client) GetGitBackup(ctx context.Context, id string) (*GitBackup, error) {
+	var backup GitBackup
+	err := c.get(ctx, backupPath("git", id), &backup)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &backup, err
+}
+
+// CreateGitBackup creates backup and returns its ID.
+func (c *Client) CreateGitBackup(ctx context.Context, backup *GitBackup) (string, error) {
+	return c.create(ctx, backupPath("git"), backup)
+}
+
+func (c *Client) UpdateGitBackup(ctx context.Context, id string, backup *GitBackup) error {
+	return c.call(ctx, http.MethodPut, backupPath("git", id), backup, nil)
+}
+
+// DeleteGitBackup deletes the backup with the given ID of serverID, which
+// Roxy-WI connects to with credID to remove the backup job.
+func (c *Client) DeleteGitBackup(ctx context.Context, id string, serverID, credID int) error {
+	ref := &backupJobRef{ServerID: Int(serverID), CredID: Int(credID)}
+	return c.call(ctx, http.MethodDelete, backupPath("git", id), ref, nil)
+}
+
+func (c *Client) GetS3Backup(ctx context.Context, id string) (*S3Backup, error) {
+	var backup S3Backup
+	err := c.get(ctx, backupPath("s3", id), &backup)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &backup, err
+}
+
+// CreateS3Backup creates backup and returns its ID.
+func (c *Client) CreateS3Backup(ctx context.Context, backup *S3Backup) (string, error) {
+	return c.create(ctx, backupPath("s3"), backup)
+}
+
+func (c *Client) UpdateS3Backup(ctx context.Context, id string, backup *S3Backup) error {
+	return c.call(ctx, http.MethodPut, backupPath("s3", id), backup, nil)
+}
+
+// DeleteS3Backup deletes the backup with the given ID of serverID to bucket.
+func (c *Client) DeleteS3Backup(ctx context.Context, id string, serverID int, bucket string) error {
+	ref := &s3BackupRef{Bucket: String(bucket), ServerID: Int(serverID)}
+	return c.call(ctx, http.MethodDelete, backupPath("s3", id), ref, nil)
+}
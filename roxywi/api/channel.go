@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Channel is a notification channel, e.g. a Telegram chat or a Slack
+// channel. Its API path depends on its receiver type.
+type Channel struct {
+	Receiver String `json:"receiver"`
+	Channel  String `json:"channel"`
+	// GroupID is nil if Roxy-WI left it out of the response.
+	GroupID *Int   `json:"group_id"`
+	Token   String `json:"token"`
+}
+
+func (c *Client) GetChannel(ctx context.Context, receiver, id string) (*Channel, error) {
+	var channel Channel
+	err := c.get(ctx, Path("api", "channel", receiver, id), &channel)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &channel, err
+}
+
+// CreateChannel creates channel and returns its ID.
+func (c *Client) CreateChannel(ctx context.Context, channel *Channel) (string, error) {
+	return c.create(ctx, Path("api", "channel", channel.Receiver), channel)
+}
+
+func (c *Client) UpdateChannel(ctx context.Context, id string, channel *Channel) error {
+	return c.call(ctx, http.MethodPut, Path("api", "channel", channel.Receiver, id), channel, nil)
+}
+
+func (c *Client) DeleteChannel(ctx context.Context, receiver, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "channel", receiver, id), nil, nil)
+}
@@ -0,0 +1,99 @@
+// Package api is a typed client for the Roxy-WI REST API.
+//
+// It does not deal with authentication or transport concerns itself: every
+// call goes through a Doer, which in the provider is the authenticated
+// roxywi.Client.
+//
+// Objects are decoded field by field. A field that cannot be decoded does
+// not fail the whole call: the object is returned with the other fields set,
+// together with a *DecodeError naming the fields that failed.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Doer performs an authenticated request against the Roxy-WI API. body is
+// encoded as JSON; the raw response body is returned for 2xx responses.
+type Doer interface {
+	DoRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error)
+}
+
+type Client struct {
+	doer Doer
+}
+
+func New(doer Doer) *Client {
+	return &Client{doer: doer}
+}
+
+// IsNotFound reports whether err is a 404 response from Roxy-WI.
+func IsNotFound(err error) bool {
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.HTTPStatusCode() == http.StatusNotFound
+	}
+	return false
+}
+
+// createdID is the response Roxy-WI sends after creating an object. Depending
+// on the endpoint the ID is a number or a string.
+type createdID struct {
+	ID String `json:"id"`
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, out interface{}) error {
+	return c.call(ctx, http.MethodGet, endpoint, nil, out)
+}
+
+func (c *Client) create(ctx context.Context, endpoint string, body interface{}) (string, error) {
+	return c.callForID(ctx, http.MethodPost, endpoint, body)
+}
+
+// callForID sends body and returns the ID Roxy-WI answers with, see
+// createdID.
+func (c *Client) callForID(ctx context.Context, method, endpoint string, body interface{}) (string, error) {
+	var result createdID
+	if err := c.call(ctx, method, endpoint, body, &result); err != nil {
+		return "", err
+	}
+	if result.ID == "" {
+		return "", fmt.Errorf("%s %s: unable to find ID in response", method, endpoint)
+	}
+	return string(result.ID), nil
+}
+
+func (c *Client) call(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	resp, err := c.doer.DoRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return decode(method, endpoint, resp, out)
+}
+
+// decode decodes the response to method endpoint into out, field by field if
+// out points to a struct or to a slice of structs.
+func decode(method, endpoint string, data []byte, out interface{}) error {
+	if !decodesFields(out) {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%s %s: unable to decode response: %w", method, endpoint, err)
+		}
+		return nil
+	}
+
+	fieldErrs, err := decodeFields(data, out)
+	if err != nil {
+		return fmt.Errorf("%s %s: unable to decode response: %w", method, endpoint, err)
+	}
+	if len(fieldErrs) > 0 {
+		return &DecodeError{Method: method, Endpoint: endpoint, Fields: fieldErrs}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldError is a field of a response object that could not be decoded.
+type FieldError struct {
+	// Index is the position of the object in a list response, and 0 for
+	// responses holding a single object.
+	Index int
+	// Field is the JSON key of the field, e.g. "port".
+	Field string
+	Err   error
+}
+
+// DecodeError is returned when some fields of a response object cannot be
+// decoded. The other fields are decoded all the same, and the methods that
+// return a DecodeError return the partially decoded object with it.
+type DecodeError struct {
+	Method   string
+	Endpoint string
+	Fields   []FieldError
+}
+
+func (e *DecodeError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, fmt.Sprintf("%q: %v", field.Field, field.Err))
+	}
+	return fmt.Sprintf("%s %s: unable to decode response: %s", e.Method, e.Endpoint, strings.Join(fields, "; "))
+}
+
+// IsDecodeError reports whether err is a *DecodeError, which means that the
+// object returned with it is usable apart from the fields listed in it.
+func IsDecodeError(err error) bool {
+	var decodeErr *DecodeError
+	return errors.As(err, &decodeErr)
+}
+
+// ItemError returns the part of err that concerns the index-th object of a
+// list response: a *DecodeError with its fields only, nil if all of them
+// were decoded, or err itself if it is not a *DecodeError.
+func ItemError(err error, index int) error {
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		return err
+	}
+
+	var fields []FieldError
+	for _, field := range decodeErr.Fields {
+		if field.Index == index {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &DecodeError{Method: decodeErr.Method, Endpoint: decodeErr.Endpoint, Fields: fields}
+}
+
+// decodeFields decodes data into out, a pointer to a struct or to a slice of
+// structs, one field at a time, see decodeObject.
+func decodeFields(data []byte, out interface{}) ([]FieldError, error) {
+	v := reflect.ValueOf(out).Elem()
+	if v.Kind() == reflect.Struct {
+		return decodeObject(data, out)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	var fieldErrs []FieldError
+	for i, item := range items {
+		errs, err := decodeObject(item, slice.Index(i).Addr().Interface())
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		for _, fieldErr := range errs {
+			fieldErr.Index = i
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+	}
+	if items != nil {
+		v.Set(slice)
+	}
+	return fieldErrs, nil
+}
+
+// decodeObject decodes the JSON object data into the struct out points to
+// one field at a time, so that a malformed field does not prevent the others
+// from being decoded. The fields that fail are returned as FieldErrors in
+// the order of the struct.
+func decodeObject(data []byte, out interface{}) ([]FieldError, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(out).Elem()
+	t := v.Type()
+
+	var fieldErrs []FieldError
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, v.Field(i).Addr().Interface()); err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: key, Err: err})
+		}
+	}
+	return fieldErrs, nil
+}
+
+// decodesFields reports whether out points to a struct or to a slice of
+// structs, which decodeFields can decode field by field.
+func decodesFields(out interface{}) bool {
+	t := reflect.TypeOf(out)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return false
+	}
+	t = t.Elem()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type doerFunc func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error)
+
+func (f doerFunc) DoRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	return f(ctx, method, endpoint, body)
+}
+
+func TestGetServerMalformedField(t *testing.T) {
+	client := New(doerFunc(func(context.Context, string, string, interface{}) ([]byte, error) {
+		return []byte(`{"id": 1, "hostname": "haproxy-1", "port": [22], "enabled": "maybe"}`), nil
+	}))
+
+	server, err := client.GetServer(context.Background(), "1")
+	if server == nil {
+		t.Fatalf("GetServer() returned no server with error %v", err)
+	}
+	if server.Hostname != "haproxy-1" || server.ID != 1 {
+		t.Errorf("GetServer() = %+v, want the well-formed fields decoded", server)
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("GetServer() error = %v, want a *DecodeError", err)
+	}
+	var fields []string
+	for _, field := range decodeErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	if len(fields) != 2 || fields[0] != "enabled" || fields[1] != "port" {
+		t.Errorf("DecodeError fields = %v, want [enabled port] in struct order", fields)
+	}
+}
+
+func TestGetServerNotAnObject(t *testing.T) {
+	client := New(doerFunc(func(context.Context, string, string, interface{}) ([]byte, error) {
+		return []byte(`[]`), nil
+	}))
+
+	server, err := client.GetServer(context.Background(), "1")
+	if err == nil || server != nil || IsDecodeError(err) {
+		t.Errorf("GetServer() = %+v, %v, want only a plain decode error", server, err)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Group is a user-defined pool of servers.
+type Group struct {
+	ID          Int    `json:"group_id,omitempty"` // set in group lists only
+	Name        String `json:"name"`
+	Description String `json:"description"`
+}
+
+func (c *Client) GetGroup(ctx context.Context, id string) (*Group, error) {
+	var group Group
+	err := c.get(ctx, Path("api", "group", id), &group)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &group, err
+}
+
+// ListGroups returns every group the user can see. The groups are returned
+// together with a *DecodeError if some of their fields could not be decoded,
+// see ItemError.
+func (c *Client) ListGroups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	err := c.get(ctx, Path("api", "groups"), &groups)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return groups, err
+}
+
+// CreateGroup creates group and returns its ID.
+func (c *Client) CreateGroup(ctx context.Context, group *Group) (string, error) {
+	return c.create(ctx, Path("api", "group"), group)
+}
+
+func (c *Client) UpdateGroup(ctx context.Context, id string, group *Group) error {
+	return c.call(ctx, http.MethodPut, Path("api", "group", id), group, nil)
+}
+
+func (c *Client) DeleteGroup(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "group", id), nil, nil)
+}
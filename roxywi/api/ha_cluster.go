@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// HaCluster is a keepalived based HA cluster.
+type HaCluster struct {
+	Description  String                      `json:"description"`
+	Name         String                      `json:"name"`
+	ReturnMaster IntBool                     `json:"return_master"`
+	Servers      HaClusterServers            `json:"servers"`
+	Services     map[string]HaClusterService `json:"services"`
+	SynFlood     IntBool                     `json:"syn_flood"`
+	UseSrc       IntBool                     `json:"use_src"`
+	VIP          String                      `json:"vip"`
+	VirtServer   IntBool                     `json:"virt_server"`
+	// Reconfigure makes Roxy-WI redeploy keepalived on the cluster servers.
+	Reconfigure bool `json:"reconfigure,omitempty"`
+}
+
+type HaClusterServer struct {
+	ID     Int     `json:"id"`
+	Eth    String  `json:"eth"`
+	Master IntBool `json:"master"`
+}
+
+// HaClusterServers may be returned as a Python-style string.
+type HaClusterServers []HaClusterServer
+
+func (s *HaClusterServers) UnmarshalJSON(data []byte) error {
+	var list ConfigList
+	if err := list.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	// Round-trip through JSON so that the tolerant field types apply.
+	encoded, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	var servers []HaClusterServer
+	if err := json.Unmarshal(encoded, &servers); err != nil {
+		return err
+	}
+	*s = servers
+	return nil
+}
+
+type HaClusterService struct {
+	Docker  IntBool `json:"docker"`
+	Enabled IntBool `json:"enabled"`
+}
+
+func (c *Client) GetHaCluster(ctx context.Context, id string) (*HaCluster, error) {
+	var cluster HaCluster
+	err := c.get(ctx, Path("api", "ha", "cluster", id), &cluster)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &cluster, err
+}
+
+// CreateHaCluster creates cluster and returns its ID.
+func (c *Client) CreateHaCluster(ctx context.Context, cluster *HaCluster) (string, error) {
+	return c.create(ctx, Path("api", "ha", "cluster"), cluster)
+}
+
+func (c *Client) UpdateHaCluster(ctx context.Context, id string, cluster *HaCluster) error {
+	return c.call(ctx, http.MethodPut, Path("api", "ha", "cluster", id), cluster, nil)
+}
+
+func (c *Client) DeleteHaCluster(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "ha", "cluster", id), nil, nil)
+}
+
+// HaClusterVip is an additional virtual IP address of an HA cluster.
+type HaClusterVip struct {
+	ClusterID    Int              `json:"cluster_id"`
+	ReturnMaster IntBool          `json:"return_master"`
+	Servers      HaClusterServers `json:"servers"`
+	UseSrc       IntBool          `json:"use_src"`
+	VIP          String           `json:"vip"`
+	VirtServer   IntBool          `json:"virt_server"`
+	// Reconfigure makes Roxy-WI redeploy keepalived on the cluster servers.
+	Reconfigure bool `json:"reconfigure,omitempty"`
+}
+
+func haClusterVipPath(clusterID interface{}, vipID ...interface{}) string {
+	return Path(append([]interface{}{"api", "ha", "cluster", clusterID, "vip"}, vipID...)...)
+}
+
+func (c *Client) GetHaClusterVip(ctx context.Context, clusterID, vipID string) (*HaClusterVip, error) {
+	var vip HaClusterVip
+	err := c.get(ctx, haClusterVipPath(clusterID, vipID), &vip)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &vip, err
+}
+
+// ListHaClusterVips returns the virtual IP addresses of the cluster, its
+// main one included.
+func (c *Client) ListHaClusterVips(ctx context.Context, clusterID int) ([]HaClusterVip, error) {
+	var vips []HaClusterVip
+	err := c.get(ctx, Path("api", "ha", "cluster", clusterID, "vips"), &vips)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return vips, err
+}
+
+// CreateHaClusterVip adds vip to its cluster and returns its ID.
+func (c *Client) CreateHaClusterVip(ctx context.Context, vip *HaClusterVip) (string, error) {
+	return c.create(ctx, haClusterVipPath(int(vip.ClusterID)), vip)
+}
+
+func (c *Client) UpdateHaClusterVip(ctx context.Context, vipID string, vip *HaClusterVip) error {
+	return c.call(ctx, http.MethodPut, haClusterVipPath(int(vip.ClusterID), vipID), vip, nil)
+}
+
+func (c *Client) DeleteHaClusterVip(ctx context.Context, clusterID, vipID string) error {
+	return c.call(ctx, http.MethodDelete, haClusterVipPath(clusterID, vipID), nil, nil)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// HaproxyList is a black or white list of IP addresses that HAProxy sections
+// refer to. Lists belong to a group and are identified by their name and
+// color.
+type HaproxyList struct {
+	Name     String `json:"name"`
+	Color    String `json:"color"`
+	Content  String `json:"content"`
+	ServerIP String `json:"server_ip"`
+	Action   String `json:"action"`
+	GroupID  Int    `json:"group_id"`
+}
+
+// haproxyListRef names a list in the requests that do not take its content.
+type haproxyListRef struct {
+	Name    String `json:"name"`
+	Color   String `json:"color"`
+	GroupID Int    `json:"group_id"`
+}
+
+func (c *Client) GetHaproxyList(ctx context.Context, name, color string) (*HaproxyList, error) {
+	var list HaproxyList
+	err := c.get(ctx, Path("api", "service", "haproxy", "list", name, color), &list)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &list, err
+}
+
+// CreateHaproxyList creates list and uploads it to the server at its
+// ServerIP. The ID of the list is returned.
+func (c *Client) CreateHaproxyList(ctx context.Context, list *HaproxyList) (string, error) {
+	return c.create(ctx, Path("api", "service", "haproxy", "list"), list)
+}
+
+func (c *Client) UpdateHaproxyList(ctx context.Context, list *HaproxyList) error {
+	return c.call(ctx, http.MethodPut, Path("api", "service", "haproxy", "list"), list, nil)
+}
+
+func (c *Client) DeleteHaproxyList(ctx context.Context, name, color string, groupID int) error {
+	ref := &haproxyListRef{Name: String(name), Color: String(color), GroupID: Int(groupID)}
+	return c.call(ctx, http.MethodDelete, Path("api", "service", "haproxy", "list"), ref, nil)
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BackendSection is a backend section of haproxy.cfg. Nested blocks are kept
+// as generic objects, they mirror the provider schema one to one.
+type BackendSection struct {
+	Name            String     `json:"name"`
+	Type            String     `json:"type"`
+	ServerID        Int        `json:"server_id"`
+	Action          String     `json:"action,omitempty"`
+	Balance         String     `json:"balance"`
+	Mode            String     `json:"mode"`
+	Blacklist       String     `json:"blacklist,omitempty"`
+	Whitelist       String     `json:"whitelist,omitempty"`
+	BackendServers  ConfigList `json:"backend_servers"`
+	Acls            ConfigList `json:"acls"`
+	Headers         ConfigList `json:"headers"`
+	CircuitBreaking ConfigMap  `json:"circuit_breaking"`
+	ServersCheck    ConfigMap  `json:"servers_check"`
+	Ssl             ConfigMap  `json:"ssl"`
+	HealthCheck     ConfigMap  `json:"health_check"`
+	Cookie          ConfigMap  `json:"cookie"`
+	Cache           Bool       `json:"cache"`
+	Compression     Bool       `json:"compression"`
+	ForwardFor      Bool       `json:"forward_for"`
+	SslOffloading   Bool       `json:"ssl_offloading"`
+	Redispatch      Bool       `json:"redispatch"`
+}
+
+// FrontendSection is a frontend section of haproxy.cfg.
+type FrontendSection struct {
+	Name          String     `json:"name"`
+	Type          String     `json:"type"`
+	ServerID      Int        `json:"server_id"`
+	Action        String     `json:"action"`
+	Mode          String     `json:"mode"`
+	Blacklist     String     `json:"blacklist"`
+	Whitelist     String     `json:"whitelist"`
+	UseBackend    String     `json:"backends"`
+	Binds         ConfigList `json:"binds"`
+	Acls          ConfigList `json:"acls"`
+	Headers       ConfigList `json:"headers"`
+	Ssl           ConfigMap  `json:"ssl"`
+	Cache         Bool       `json:"cache"`
+	Compression   Bool       `json:"compression"`
+	ForwardFor    Bool       `json:"forward_for"`
+	SslOffloading Bool       `json:"ssl_offloading"`
+	SlowAttack    Bool       `json:"slow_attack"`
+	AntiBot       Bool       `json:"antibot"`
+	Ddos          Bool       `json:"ddos"`
+	Waf           Bool       `json:"waf"`
+	Maxconn       Int        `json:"maxconn"`
+}
+
+// ListenSection is a listen section of haproxy.cfg, a frontend and a backend
+// in one.
+type ListenSection struct {
+	Name            String     `json:"name"`
+	Type            String     `json:"type"`
+	ServerID        Int        `json:"server_id"`
+	Action          String     `json:"action"`
+	Balance         String     `json:"balance"`
+	Mode            String     `json:"mode"`
+	Blacklist       String     `json:"blacklist"`
+	Whitelist       String     `json:"whitelist"`
+	Binds           ConfigList `json:"binds"`
+	BackendServers  ConfigList `json:"backend_servers"`
+	Acls            ConfigList `json:"acls"`
+	Headers         ConfigList `json:"headers"`
+	CircuitBreaking ConfigMap  `json:"circuit_breaking"`
+	ServersCheck    ConfigMap  `json:"servers_check"`
+	Ssl             ConfigMap  `json:"ssl"`
+	HealthCheck     ConfigMap  `json:"health_check"`
+	Cookie          ConfigMap  `json:"cookie"`
+	Cache           Bool       `json:"cache"`
+	Compression     Bool       `json:"compression"`
+	ForwardFor      Bool       `json:"forward_for"`
+	SslOffloading   Bool       `json:"ssl_offloading"`
+	SlowAttack      Bool       `json:"slow_attack"`
+	AntiBot         Bool       `json:"antibot"`
+	Ddos            Bool       `json:"ddos"`
+	Waf             Bool       `json:"waf"`
+	Redispatch      Bool       `json:"redispatch"`
+	Maxconn         Int        `json:"maxconn"`
+}
+
+// PeersSection is a peers section of haproxy.cfg.
+type PeersSection struct {
+	Name     String     `json:"name"`
+	Type     String     `json:"type"`
+	ServerID Int        `json:"server_id"`
+	Action   String     `json:"action"`
+	Peers    ConfigList `json:"peers"`
+}
+
+// UserlistSection is a userlist section of haproxy.cfg.
+type UserlistSection struct {
+	Name     String     `json:"name"`
+	Type     String     `json:"type"`
+	ServerID Int        `json:"server_id"`
+	Action   String     `json:"action"`
+	Users    ConfigList `json:"userlist_users"`
+	Groups   StringList `json:"userlist_groups"`
+}
+
+// DefaultsSection is the defaults section of haproxy.cfg, which exists once
+// per server and has no name.
+type DefaultsSection struct {
+	Type     String    `json:"type"`
+	ServerID Int       `json:"server_id"`
+	Action   String    `json:"action"`
+	Log      String    `json:"log"`
+	Maxconn  Int       `json:"maxconn"`
+	Option   String    `json:"option"`
+	Retries  Int       `json:"retries"`
+	Timeout  ConfigMap `json:"timeout"`
+}
+
+// GlobalSection is the global section of haproxy.cfg, which exists once per
+// server and has no name.
+type GlobalSection struct {
+	Type     String     `json:"type"`
+	ServerID Int        `json:"server_id"`
+	Action   String     `json:"action"`
+	Log      StringList `json:"log"`
+	Socket   StringList `json:"socket"`
+	Maxconn  Int        `json:"maxconn"`
+	Option   String     `json:"option"`
+	PidFile  String     `json:"pidfile"`
+	Daemon   Bool       `json:"daemon"`
+	User     String     `json:"user"`
+	Group    String     `json:"group"`
+	Chroot   String     `json:"chroot"`
+}
+
+// sectionPath returns the sectionType sections of serverID, or the section
+// named by the optional name.
+func sectionPath(serverID int, sectionType string, name ...interface{}) string {
+	return Path(append([]interface{}{"api", "service", "haproxy", serverID, "section", sectionType}, name...)...)
+}
+
+// upsertSection writes section, the sectionType section called name, to
+// haproxy.cfg on serverID. A new section is created when create is true,
+// otherwise the existing section with the same name is replaced. The ID of
+// the section is returned.
+func (c *Client) upsertSection(ctx context.Context, serverID int, sectionType, name string, section interface{}, create bool) (string, error) {
+	if create {
+		return c.create(ctx, sectionPath(serverID, sectionType), section)
+	}
+
+	if err := c.call(ctx, http.MethodPut, sectionPath(serverID, sectionType, name), section, nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", serverID, name), nil
+}
+
+// DeleteHaproxySection deletes the sectionType section called name from
+// haproxy.cfg on serverID.
+func (c *Client) DeleteHaproxySection(ctx context.Context, serverID int, sectionType, name string) error {
+	return c.call(ctx, http.MethodDelete, sectionPath(serverID, sectionType, name), nil, nil)
+}
+
+func (c *Client) GetBackendSection(ctx context.Context, serverID int, name string) (*BackendSection, error) {
+	var section BackendSection
+	err := c.get(ctx, sectionPath(serverID, "backend", name), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpsertBackendSection writes section to haproxy.cfg on serverID, see
+// upsertSection.
+func (c *Client) UpsertBackendSection(ctx context.Context, serverID int, section *BackendSection, create bool) (string, error) {
+	section.Type = "backend"
+	section.ServerID = Int(serverID)
+	return c.upsertSection(ctx, serverID, "backend", string(section.Name), section, create)
+}
+
+func (c *Client) DeleteBackendSection(ctx context.Context, serverID int, name string) error {
+	return c.DeleteHaproxySection(ctx, serverID, "backend", name)
+}
+
+func (c *Client) GetFrontendSection(ctx context.Context, serverID int, name string) (*FrontendSection, error) {
+	var section FrontendSection
+	err := c.get(ctx, sectionPath(serverID, "frontend", name), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpsertFrontendSection writes section to haproxy.cfg on serverID, see
+// upsertSection.
+func (c *Client) UpsertFrontendSection(ctx context.Context, serverID int, section *FrontendSection, create bool) (string, error) {
+	section.Type = "frontend"
+	section.ServerID = Int(serverID)
+	return c.upsertSection(ctx, serverID, "frontend", string(section.Name), section, create)
+}
+
+func (c *Client) GetListenSection(ctx context.Context, serverID int, name string) (*ListenSection, error) {
+	var section ListenSection
+	err := c.get(ctx, sectionPath(serverID, "listen", name), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpsertListenSection writes section to haproxy.cfg on serverID, see
+// upsertSection.
+func (c *Client) UpsertListenSection(ctx context.Context, serverID int, section *ListenSection, create bool) (string, error) {
+	section.Type = "listen"
+	section.ServerID = Int(serverID)
+	return c.upsertSection(ctx, serverID, "listen", string(section.Name), section, create)
+}
+
+func (c *Client) GetPeersSection(ctx context.Context, serverID int, name string) (*PeersSection, error) {
+	var section PeersSection
+	err := c.get(ctx, sectionPath(serverID, "peers", name), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpsertPeersSection writes section to haproxy.cfg on serverID, see
+// upsertSection.
+func (c *Client) UpsertPeersSection(ctx context.Context, serverID int, section *PeersSection, create bool) (string, error) {
+	section.Type = "peers"
+	section.ServerID = Int(serverID)
+	return c.upsertSection(ctx, serverID, "peers", string(section.Name), section, create)
+}
+
+func (c *Client) GetUserlistSection(ctx context.Context, serverID int, name string) (*UserlistSection, error) {
+	var section UserlistSection
+	err := c.get(ctx, sectionPath(serverID, "userlist", name), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpsertUserlistSection writes section to haproxy.cfg on serverID, see
+// upsertSection.
+func (c *Client) UpsertUserlistSection(ctx context.Context, serverID int, section *UserlistSection, create bool) (string, error) {
+	section.Type = "userlist"
+	section.ServerID = Int(serverID)
+	return c.upsertSection(ctx, serverID, "userlist", string(section.Name), section, create)
+}
+
+func (c *Client) GetDefaultsSection(ctx context.Context, serverID int) (*DefaultsSection, error) {
+	var section DefaultsSection
+	err := c.get(ctx, sectionPath(serverID, "defaults"), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpdateDefaultsSection replaces the defaults section of haproxy.cfg on
+// serverID.
+func (c *Client) UpdateDefaultsSection(ctx context.Context, serverID int, section *DefaultsSection) error {
+	section.Type = "defaults"
+	section.ServerID = Int(serverID)
+	return c.call(ctx, http.MethodPut, sectionPath(serverID, "defaults"), section, nil)
+}
+
+func (c *Client) GetGlobalSection(ctx context.Context, serverID int) (*GlobalSection, error) {
+	var section GlobalSection
+	err := c.get(ctx, sectionPath(serverID, "global"), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpdateGlobalSection replaces the global section of haproxy.cfg on
+// serverID.
+func (c *Client) UpdateGlobalSection(ctx context.Context, serverID int, section *GlobalSection) error {
+	section.Type = "global"
+	section.ServerID = Int(serverID)
+	return c.call(ctx, http.MethodPut, sectionPath(serverID, "global"), section, nil)
+}
+
+// ListedSectionTypes are the HAProxy section types that can exist more than
+// once per server and can therefore be listed.
+var ListedSectionTypes = []string{"frontend", "backend", "listen", "peers", "userlist"}
+
+// sectionName is an entry of a section listing. Depending on the release,
+// Roxy-WI returns the bare name or the whole section.
+type sectionName string
+
+func (n *sectionName) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*n = sectionName(name)
+		return nil
+	}
+
+	var object struct {
+		Name String `json:"name"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("expected a section name or a section object, got %s", data)
+	}
+	if object.Name == "" {
+		return fmt.Errorf("no section name in %s", data)
+	}
+	*n = sectionName(object.Name)
+	return nil
+}
+
+// ListHaproxySections returns the names of the sections of sectionType in
+// haproxy.cfg on serverID. A server without such sections yields an empty
+// list, whether Roxy-WI answers with an empty list or with a 404.
+func (c *Client) ListHaproxySections(ctx context.Context, serverID int, sectionType string) ([]string, error) {
+	var entries []sectionName
+	err := c.get(ctx, sectionPath(serverID, sectionType), &entries)
+	if err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, string(entry))
+	}
+	return names, nil
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Installation is a service Roxy-WI installed on a server, together with the
+// tools enabled for it.
+type Installation struct {
+	AutoStart IntBool `json:"auto_start"`
+	Checker   IntBool `json:"checker"`
+	Metrics   IntBool `json:"metrics"`
+	Docker    IntBool `json:"docker"`
+	ServerID  Int     `json:"server_id,omitempty"` // set in responses only
+	Service   String  `json:"service,omitempty"`   // set in responses only
+}
+
+func installationPath(service string, serverID interface{}) string {
+	return Path("api", "service", service, serverID, "install")
+}
+
+func (c *Client) GetInstallation(ctx context.Context, service, serverID string) (*Installation, error) {
+	var inst Installation
+	err := c.get(ctx, installationPath(service, serverID), &inst)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &inst, err
+}
+
+// InstallService installs service on the server and returns the ID of the
+// installation.
+func (c *Client) InstallService(ctx context.Context, service string, serverID int, inst *Installation) (string, error) {
+	return c.create(ctx, installationPath(service, serverID), inst)
+}
+
+// ReinstallService installs service on the server again with the tools of
+// inst and returns the ID of the installation.
+func (c *Client) ReinstallService(ctx context.Context, service string, serverID int, inst *Installation) (string, error) {
+	return c.callForID(ctx, http.MethodPut, installationPath(service, serverID), inst)
+}
+
+func (c *Client) UninstallService(ctx context.Context, service string, serverID int) error {
+	return c.call(ctx, http.MethodDelete, installationPath(service, serverID), nil, nil)
+}
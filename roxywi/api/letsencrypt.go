@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Certificate is a Let's Encrypt certificate Roxy-WI issues and deploys to a
+// server.
+type Certificate struct {
+	Description String     `json:"description"`
+	Domains     StringList `json:"domains"`
+	ServerID    Int        `json:"server_id"`
+	APIToken    String     `json:"api_token"`
+	APIKey      String     `json:"api_key"`
+	Email       String     `json:"email"`
+	Type        String     `json:"type"`
+}
+
+func (c *Client) GetCertificate(ctx context.Context, id string) (*Certificate, error) {
+	var cert Certificate
+	err := c.get(ctx, Path("api", "service", "letsencrypt", id), &cert)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &cert, err
+}
+
+// CreateCertificate requests cert and returns its ID.
+func (c *Client) CreateCertificate(ctx context.Context, cert *Certificate) (string, error) {
+	return c.create(ctx, Path("api", "service", "letsencrypt"), cert)
+}
+
+func (c *Client) UpdateCertificate(ctx context.Context, id string, cert *Certificate) error {
+	return c.call(ctx, http.MethodPut, Path("api", "service", "letsencrypt", id), cert, nil)
+}
+
+func (c *Client) DeleteCertificate(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "service", "letsencrypt", id), nil, nil)
+}
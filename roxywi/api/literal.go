@@ -0,0 +1,366 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseLiteral parses a Python literal as produced by repr() in Roxy-WI,
+// e.g. "[{'ip': '10.0.0.1', 'port': 80, 'backup': False}]". Dicts become
+// map[string]interface{}, lists and tuples []interface{}, numbers float64,
+// True/False bool and None nil, matching what encoding/json produces for the
+// equivalent JSON. JSON literals true, false and null are accepted as well.
+func ParseLiteral(s string) (interface{}, error) {
+	p := &literalParser{input: s}
+	p.skipSpace()
+	value, err := p.value(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q after value", p.input[p.pos])
+	}
+	return value, nil
+}
+
+// maxLiteralDepth bounds nesting so that hostile input cannot exhaust the
+// stack.
+const maxLiteralDepth = 100
+
+type literalParser struct {
+	input string
+	pos   int
+}
+
+func (p *literalParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid Python literal at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *literalParser) skipSpace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *literalParser) value(depth int) (interface{}, error) {
+	if depth > maxLiteralDepth {
+		return nil, p.errorf("nesting deeper than %d levels", maxLiteralDepth)
+	}
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '{':
+		return p.dict(depth)
+	case c == '[':
+		return p.list(depth, ']')
+	case c == '(':
+		return p.list(depth, ')')
+	case c == '\'' || c == '"':
+		return p.string(false)
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case isIdentStart(c):
+		return p.identifier()
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *literalParser) dict(depth int) (interface{}, error) {
+	p.pos++ // {
+	result := make(map[string]interface{})
+
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			return result, nil
+		}
+
+		key, err := p.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(':') {
+			return nil, p.errorf("expected ':' after dict key")
+		}
+		p.skipSpace()
+		value, err := p.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[literalKey(key)] = value
+
+		p.skipSpace()
+		if p.consume(',') {
+			continue
+		}
+		if p.consume('}') {
+			return result, nil
+		}
+		return nil, p.errorf("expected ',' or '}' in dict")
+	}
+}
+
+func (p *literalParser) list(depth int, end byte) (interface{}, error) {
+	p.pos++ // [ or (
+	result := make([]interface{}, 0)
+
+	for {
+		p.skipSpace()
+		if p.consume(end) {
+			return result, nil
+		}
+
+		value, err := p.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+
+		p.skipSpace()
+		if p.consume(',') {
+			continue
+		}
+		if p.consume(end) {
+			return result, nil
+		}
+		return nil, p.errorf("expected ',' or %q in list", end)
+	}
+}
+
+// string parses a quoted string, including triple-quoted ones. Adjacent
+// literals are concatenated as in Python.
+func (p *literalParser) string(raw bool) (interface{}, error) {
+	var b strings.Builder
+	for {
+		if err := p.quoted(&b, raw); err != nil {
+			return nil, err
+		}
+
+		save := p.pos
+		p.skipSpace()
+		raw = p.stringPrefix()
+		if p.pos < len(p.input) && (p.input[p.pos] == '\'' || p.input[p.pos] == '"') {
+			continue
+		}
+		p.pos = save
+		return b.String(), nil
+	}
+}
+
+// stringPrefix consumes a u, b or r string prefix if one is followed by a
+// quote, and reports whether it makes the string raw.
+func (p *literalParser) stringPrefix() bool {
+	start := p.pos
+	raw := false
+	for p.pos < len(p.input) && p.pos-start < 2 {
+		switch p.input[p.pos] {
+		case 'r', 'R':
+			raw = true
+		case 'u', 'U', 'b', 'B':
+		default:
+			if p.input[p.pos] != '\'' && p.input[p.pos] != '"' {
+				p.pos = start
+				return false
+			}
+			return raw
+		}
+		p.pos++
+	}
+	if p.pos < len(p.input) && (p.input[p.pos] == '\'' || p.input[p.pos] == '"') {
+		return raw
+	}
+	p.pos = start
+	return false
+}
+
+func (p *literalParser) quoted(b *strings.Builder, raw bool) error {
+	quote := p.input[p.pos : p.pos+1]
+	if strings.HasPrefix(p.input[p.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	p.pos += len(quote)
+
+	for {
+		if p.pos >= len(p.input) {
+			return p.errorf("unterminated string")
+		}
+		if strings.HasPrefix(p.input[p.pos:], quote) {
+			p.pos += len(quote)
+			return nil
+		}
+
+		c := p.input[p.pos]
+		if c == '\n' && len(quote) == 1 {
+			return p.errorf("newline in string")
+		}
+		if c != '\\' {
+			_, size := utf8.DecodeRuneInString(p.input[p.pos:])
+			b.WriteString(p.input[p.pos : p.pos+size])
+			p.pos += size
+			continue
+		}
+
+		if p.pos+1 >= len(p.input) {
+			return p.errorf("unterminated string")
+		}
+		if raw {
+			b.WriteString(p.input[p.pos : p.pos+2])
+			p.pos += 2
+			continue
+		}
+		if err := p.escape(b); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *literalParser) escape(b *strings.Builder) error {
+	c := p.input[p.pos+1]
+	p.pos += 2
+
+	switch c {
+	case '\n':
+		// Line continuation.
+	case '\\', '\'', '"':
+		b.WriteByte(c)
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'a':
+		b.WriteByte('\a')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case 'x':
+		return p.codePoint(b, 2)
+	case 'u':
+		return p.codePoint(b, 4)
+	case 'U':
+		return p.codePoint(b, 8)
+	default:
+		if c >= '0' && c <= '7' {
+			end := p.pos
+			for end < len(p.input) && end < p.pos+2 && p.input[end] >= '0' && p.input[end] <= '7' {
+				end++
+			}
+			n, _ := strconv.ParseUint(p.input[p.pos-1:end], 8, 32)
+			b.WriteRune(rune(n))
+			p.pos = end
+			return nil
+		}
+		// Python keeps unknown escapes as they are.
+		b.WriteByte('\\')
+		b.WriteByte(c)
+	}
+	return nil
+}
+
+func (p *literalParser) codePoint(b *strings.Builder, digits int) error {
+	if p.pos+digits > len(p.input) {
+		return p.errorf("truncated escape sequence")
+	}
+	n, err := strconv.ParseUint(p.input[p.pos:p.pos+digits], 16, 32)
+	if err != nil || n > utf8.MaxRune {
+		return p.errorf("invalid escape sequence %q", p.input[p.pos:p.pos+digits])
+	}
+	b.WriteRune(rune(n))
+	p.pos += digits
+	return nil
+}
+
+func (p *literalParser) number() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if (c >= '0' && c <= '9') || c == '.' || c == '_' || c == 'e' || c == 'E' || c == 'x' || c == 'X' ||
+			(c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') ||
+			((c == '-' || c == '+') && (p.pos == start || p.input[p.pos-1] == 'e' || p.input[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+
+	text := strings.ReplaceAll(p.input[start:p.pos], "_", "")
+	if n, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return float64(n), nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number %q", text)
+	}
+	return f, nil
+}
+
+func (p *literalParser) identifier() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isIdentStart(p.input[p.pos]) || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+		p.pos++
+	}
+
+	switch word := p.input[start:p.pos]; word {
+	case "True", "true":
+		return true, nil
+	case "False", "false":
+		return false, nil
+	case "None", "null":
+		return nil, nil
+	default:
+		// String prefixes such as u'...' or r"...".
+		p.pos = start
+		if raw := p.stringPrefix(); p.pos > start {
+			return p.string(raw)
+		}
+		return nil, p.errorf("unexpected identifier %q", word)
+	}
+}
+
+func (p *literalParser) consume(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// literalKey converts a dict key to the string used in the resulting map.
+func literalKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case nil:
+		return "None"
+	case bool:
+		if k {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return strconv.FormatFloat(k, 'f', -1, 64)
+	default:
+		return fmt.Sprint(k)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{
+			name:  "udp listener config",
+			input: `[{'backend_ip': '10.0.0.11', 'port': 53, 'weight': 50}]`,
+			want: []interface{}{
+				map[string]interface{}{"backend_ip": "10.0.0.11", "port": float64(53), "weight": float64(50)},
+			},
+		},
+		{
+			name:  "booleans and None",
+			input: `{'send_proxy': False, 'backup': True, 'acl': None}`,
+			want:  map[string]interface{}{"send_proxy": false, "backup": true, "acl": nil},
+		},
+		{
+			name:  "JSON keywords",
+			input: `{"send_proxy": false, "backup": true, "acl": null}`,
+			want:  map[string]interface{}{"send_proxy": false, "backup": true, "acl": nil},
+		},
+		{
+			name:  "double quotes around a single quote",
+			input: `[{'user': 'admin', 'password': "p@ss'word"}]`,
+			want:  []interface{}{map[string]interface{}{"user": "admin", "password": "p@ss'word"}},
+		},
+		{
+			name:  "escapes",
+			input: `'a\'b\\c\n\t\x41\u00e9\U0001F600\101'`,
+			want:  "a'b\\c\n\tAé😀A",
+		},
+		{
+			name:  "unknown escape is kept",
+			input: `'C:\d'`,
+			want:  `C:\d`,
+		},
+		{
+			name:  "raw string",
+			input: `r'\d+\.\d+'`,
+			want:  `\d+\.\d+`,
+		},
+		{
+			name:  "unicode prefix",
+			input: `u'web'`,
+			want:  "web",
+		},
+		{
+			name:  "unicode text",
+			input: `'Группа web — «prod»'`,
+			want:  "Группа web — «prod»",
+		},
+		{
+			name:  "triple quoted",
+			input: "'''line one\nline 'two' '''",
+			want:  "line one\nline 'two' ",
+		},
+		{
+			name:  "adjacent strings",
+			input: `'web' "-" 'static'`,
+			want:  "web-static",
+		},
+		{
+			name:  "tuple",
+			input: `('haproxy', 'nginx')`,
+			want:  []interface{}{"haproxy", "nginx"},
+		},
+		{
+			name:  "trailing commas",
+			input: `{'a': [1, 2,],}`,
+			want:  map[string]interface{}{"a": []interface{}{float64(1), float64(2)}},
+		},
+		{
+			name:  "numbers",
+			input: `[-3, +4, 1.5, 1e3, 0x1f, 1_000, .5]`,
+			want:  []interface{}{float64(-3), float64(4), 1.5, float64(1000), float64(31), float64(1000), 0.5},
+		},
+		{
+			name:  "non-string keys",
+			input: `{1: 'a', True: 'b', None: 'c', 2.5: 'd'}`,
+			want:  map[string]interface{}{"1": "a", "True": "b", "None": "c", "2.5": "d"},
+		},
+		{
+			name:  "surrounding whitespace",
+			input: " \n [ ] \t",
+			want:  []interface{}{},
+		},
+		{
+			name:  "empty dict",
+			input: `{}`,
+			want:  map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLiteral(tt.input)
+			if err != nil {
+				t.Fatalf("ParseLiteral(%q) returned error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLiteral(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLiteralErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: "", wantErr: "unexpected end of input"},
+		{name: "unterminated string", input: `'web`, wantErr: "unterminated string"},
+		{name: "newline in string", input: "'a\nb'", wantErr: "newline in string"},
+		{name: "unterminated list", input: `[1, 2`, wantErr: "expected ',' or ']' in list"},
+		{name: "missing colon", input: `{'a' 1}`, wantErr: "expected ':' after dict key"},
+		{name: "missing comma", input: `[1 2]`, wantErr: "expected ',' or ']' in list"},
+		{name: "trailing data", input: `[] []`, wantErr: "unexpected '[' after value"},
+		{name: "unknown identifier", input: `nan`, wantErr: `unexpected identifier "nan"`},
+		{name: "bad number", input: `1.2.3`, wantErr: `invalid number "1.2.3"`},
+		{name: "number out of range", input: `1e999`, wantErr: `invalid number "1e999"`},
+		{name: "truncated escape", input: `'\x4'`, wantErr: "invalid escape sequence"},
+		{name: "invalid code point", input: `'\U00110000'`, wantErr: "invalid escape sequence"},
+		{name: "too deep", input: strings.Repeat("[", maxLiteralDepth+2), wantErr: "nesting deeper than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLiteral(tt.input)
+			if err == nil {
+				t.Fatalf("ParseLiteral(%q) succeeded, want error containing %q", tt.input, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseLiteral(%q) error = %q, want it to contain %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// FuzzParseLiteral checks that ParseLiteral never panics and that whatever
+// it accepts is valid JSON data, which is what its callers rely on when they
+// re-encode the result. The seed corpus in testdata/fuzz/FuzzParseLiteral
+// holds Python repr() output of the values Roxy-WI stores as literals.
+func FuzzParseLiteral(f *testing.F) {
+	f.Add(`[{'backend_ip': '10.0.0.11', 'port': 53, 'weight': 50}]`)
+	f.Add(`{'check': 10, 'client': 60, 'connect': 5}`)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		value, err := ParseLiteral(input)
+		if err != nil {
+			return
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("ParseLiteral(%q) = %#v, which cannot be encoded as JSON: %v", input, value, err)
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("JSON of ParseLiteral(%q) does not decode: %v", input, err)
+		}
+		if !strings.ContainsRune(string(data), '\uFFFD') && !reflect.DeepEqual(value, decoded) {
+			t.Errorf("ParseLiteral(%q) = %#v, but its JSON decodes to %#v", input, value, decoded)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// UpstreamSection is an upstream block of the NGINX configuration.
+type UpstreamSection struct {
+	Name           String     `json:"name"`
+	ServerID       Int        `json:"server_id"`
+	Action         String     `json:"action"`
+	Balance        String     `json:"balance"`
+	KeepAlive      Int        `json:"keepalive"`
+	BackendServers ConfigList `json:"backend_servers"`
+}
+
+// upstreamSectionPath returns the upstream sections of serverID, or the
+// section named by the optional name.
+func upstreamSectionPath(serverID int, name ...interface{}) string {
+	return Path(append([]interface{}{"api", "service", "nginx", serverID, "section", "upstream"}, name...)...)
+}
+
+func (c *Client) GetUpstreamSection(ctx context.Context, serverID int, name string) (*UpstreamSection, error) {
+	var section UpstreamSection
+	err := c.get(ctx, upstreamSectionPath(serverID, name), &section)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &section, err
+}
+
+// UpsertUpstreamSection writes section to the NGINX configuration on
+// serverID. A new section is created when create is true, otherwise the
+// existing section with the same name is replaced. The ID of the section is
+// returned.
+func (c *Client) UpsertUpstreamSection(ctx context.Context, serverID int, section *UpstreamSection, create bool) (string, error) {
+	section.ServerID = Int(serverID)
+
+	if create {
+		return c.create(ctx, upstreamSectionPath(serverID), section)
+	}
+
+	if err := c.call(ctx, http.MethodPut, upstreamSectionPath(serverID, section.Name), section, nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", serverID, section.Name), nil
+}
+
+func (c *Client) DeleteUpstreamSection(ctx context.Context, serverID int, name string) error {
+	return c.call(ctx, http.MethodDelete, upstreamSectionPath(serverID, name), nil, nil)
+}
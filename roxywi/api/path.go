@@ -0,0 +1,26 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Path builds an API endpoint from its segments, e.g.
+// Path("api", "service", "haproxy", serverID, "section", "backend", name).
+// Every segment is formatted with fmt.Sprint and path-escaped, so names
+// containing "/", spaces or non-ASCII characters stay a single segment.
+func Path(segments ...interface{}) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(fmt.Sprint(segment)))
+	}
+	return b.String()
+}
+
+// JoinURL appends endpoint to baseURL. baseURL may or may not end with a
+// slash and may carry a sub-path prefix such as https://host/roxy-wi.
+func JoinURL(baseURL, endpoint string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(endpoint, "/")
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []interface{}
+		want     string
+	}{
+		{
+			name:     "plain",
+			segments: []interface{}{"api", "service", "haproxy", 1, "section", "backend", "web"},
+			want:     "/api/service/haproxy/1/section/backend/web",
+		},
+		{
+			name:     "slash",
+			segments: []interface{}{"api", "service", "haproxy", 1, "section", "backend", "web/static"},
+			want:     "/api/service/haproxy/1/section/backend/web%2Fstatic",
+		},
+		{
+			name:     "space",
+			segments: []interface{}{"api", "group", "web servers"},
+			want:     "/api/group/web%20servers",
+		},
+		{
+			name:     "unicode",
+			segments: []interface{}{"api", "group", "серверы"},
+			want:     "/api/group/%D1%81%D0%B5%D1%80%D0%B2%D0%B5%D1%80%D1%8B",
+		},
+		{
+			name:     "reserved characters",
+			segments: []interface{}{"api", "group", "a?b#c%d"},
+			want:     "/api/group/a%3Fb%23c%25d",
+		},
+		{
+			name:     "no segments",
+			segments: nil,
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Path(tt.segments...); got != tt.want {
+				t.Errorf("Path(%q) = %q, want %q", tt.segments, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPathSurvivesRequest checks that escaped segments reach the server as
+// a single segment instead of being decoded by net/http on the way.
+func TestPathSurvivesRequest(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.EscapedPath()
+	}))
+	defer srv.Close()
+
+	endpoint := Path("api", "service", "haproxy", 1, "section", "backend", "web/static copy ü")
+	resp, err := http.Get(JoinURL(srv.URL+"/roxy-wi/", endpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := "/roxy-wi/api/service/haproxy/1/section/backend/web%2Fstatic%20copy%20%C3%BC"
+	if got != want {
+		t.Errorf("server saw %q, want %q", got, want)
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		endpoint string
+		want     string
+	}{
+		{
+			name:     "trailing slash",
+			baseURL:  "https://roxy.example.com/",
+			endpoint: "/api/servers",
+			want:     "https://roxy.example.com/api/servers",
+		},
+		{
+			name:     "no trailing slash",
+			baseURL:  "https://roxy.example.com",
+			endpoint: "/api/servers",
+			want:     "https://roxy.example.com/api/servers",
+		},
+		{
+			name:     "several trailing slashes",
+			baseURL:  "https://roxy.example.com//",
+			endpoint: "/api/servers",
+			want:     "https://roxy.example.com/api/servers",
+		},
+		{
+			name:     "relative endpoint",
+			baseURL:  "https://roxy.example.com/",
+			endpoint: "api/servers",
+			want:     "https://roxy.example.com/api/servers",
+		},
+		{
+			name:     "sub-path with trailing slash",
+			baseURL:  "https://example.com/roxy-wi/",
+			endpoint: "/api/servers",
+			want:     "https://example.com/roxy-wi/api/servers",
+		},
+		{
+			name:     "sub-path without trailing slash",
+			baseURL:  "https://example.com/roxy-wi",
+			endpoint: "/api/servers",
+			want:     "https://example.com/roxy-wi/api/servers",
+		},
+		{
+			name:     "escaped endpoint",
+			baseURL:  "https://example.com/roxy-wi",
+			endpoint: Path("api", "group", "web/static"),
+			want:     "https://example.com/roxy-wi/api/group/web%2Fstatic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinURL(tt.baseURL, tt.endpoint); got != tt.want {
+				t.Errorf("JoinURL(%q, %q) = %q, want %q", tt.baseURL, tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
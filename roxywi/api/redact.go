@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SensitiveKeys are JSON keys whose values are secrets: login and API
+// credentials, SSH keys, S3 keys and channel tokens. Their values never
+// appear in logs or recorded fixtures.
+var SensitiveKeys = []string{
+	"password",
+	"access_token",
+	"refresh_token",
+	"token",
+	"api_token",
+	"api_key",
+	"private_key",
+	"passphrase",
+	"secret_key",
+	"access_key",
+	"client_key",
+	"authorization",
+}
+
+// IsSensitiveKey reports whether key, in any case, is one of SensitiveKeys.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range SensitiveKeys {
+		if key == sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactJSON returns body with the non-empty values of sensitive keys
+// replaced by mask, at any depth and inside string values holding Python or
+// JSON literals. Bodies that are not JSON are returned unchanged.
+func RedactJSON(body []byte, mask string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(Redact(data, mask))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+// Redact replaces the non-empty values of sensitive keys in a value decoded
+// by encoding/json with mask, in place, and returns it. See RedactJSON.
+func Redact(value interface{}, mask string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if IsSensitiveKey(key) {
+				if item != nil && item != "" {
+					v[key] = mask
+				}
+				continue
+			}
+			v[key] = Redact(item, mask)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = Redact(item, mask)
+		}
+		return v
+	case string:
+		return redactLiteral(v, mask)
+	default:
+		return v
+	}
+}
+
+// redactLiteral redacts a string value that holds a Python or JSON literal
+// with sensitive keys, such as the userlist users Roxy-WI returns as
+// "[{'user': 'admin', 'password': '...'}]". The literal is replaced by the
+// JSON of its redacted value, which the provider parses just the same, or
+// masked as a whole if it cannot be parsed.
+func redactLiteral(s, mask string) interface{} {
+	if !mentionsSensitiveKey(s) {
+		return s
+	}
+
+	parsed, err := ParseLiteral(s)
+	if err != nil {
+		return mask
+	}
+	switch parsed.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return s
+	}
+
+	data, err := json.Marshal(Redact(parsed, mask))
+	if err != nil {
+		return mask
+	}
+	return string(data)
+}
+
+// mentionsSensitiveKey reports whether s contains a sensitive key in quotes,
+// as it would appear in a literal.
+func mentionsSensitiveKey(s string) bool {
+	s = strings.ToLower(s)
+	for _, key := range SensitiveKeys {
+		if strings.Contains(s, "'"+key+"'") || strings.Contains(s, `"`+key+`"`) {
+			return true
+		}
+	}
+	return false
+}
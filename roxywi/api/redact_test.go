@@ -0,0 +1,45 @@
+package api
+
+import "testing"
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "key in any case",
+			body: `{"Authorization": "Bearer abc", "PASSWORD": "hunter2"}`,
+			want: `{"Authorization":"MASK","PASSWORD":"MASK"}`,
+		},
+		{
+			name: "null and empty values are kept",
+			body: `{"token": null, "api_key": ""}`,
+			want: `{"api_key":"","token":null}`,
+		},
+		{
+			name: "Python literal in a string",
+			body: `{"users": "[{'user': 'admin', 'password': 'hunter2'}]"}`,
+			want: `{"users":"[{\"password\":\"MASK\",\"user\":\"admin\"}]"}`,
+		},
+		{
+			name: "quoted key in prose is masked as a whole",
+			body: `{"note": "set 'password' first"}`,
+			want: `{"note":"MASK"}`,
+		},
+		{
+			name: "empty body",
+			body: ``,
+			want: ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactJSON([]byte(tt.body), "MASK"); got != tt.want {
+				t.Errorf("RedactJSON(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
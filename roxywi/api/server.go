@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Server is a server registered in Roxy-WI.
+type Server struct {
+	ID          Int     `json:"id,omitempty"`
+	ServerID    Int     `json:"server_id,omitempty"` // the ID as named by the server list
+	CredID      Int     `json:"cred_id"`
+	Description String  `json:"description"`
+	Enabled     IntBool `json:"enabled"`
+	GroupID     Int     `json:"group_id"`
+	Hostname    String  `json:"hostname"`
+	IP          String  `json:"ip"`
+	Port        Int     `json:"port"`
+}
+
+// Identifier returns the ID of the server, whichever field it was sent in.
+func (s *Server) Identifier() int {
+	if s.ID != 0 {
+		return int(s.ID)
+	}
+	return int(s.ServerID)
+}
+
+func (c *Client) GetServer(ctx context.Context, id string) (*Server, error) {
+	var server Server
+	err := c.get(ctx, Path("api", "server", id), &server)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &server, err
+}
+
+// ListServers returns every server the user can see.
+func (c *Client) ListServers(ctx context.Context) ([]Server, error) {
+	var servers []Server
+	if err := c.get(ctx, Path("api", "servers"), &servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// CreateServer registers server and returns its ID.
+func (c *Client) CreateServer(ctx context.Context, server *Server) (string, error) {
+	return c.create(ctx, Path("api", "server"), server)
+}
+
+func (c *Client) UpdateServer(ctx context.Context, id string, server *Server) error {
+	return c.call(ctx, http.MethodPut, Path("api", "server", id), server, nil)
+}
+
+func (c *Client) DeleteServer(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "server", id), nil, nil)
+}
+
+// GetServerIPs returns the IP addresses configured on the server, as seen by
+// Roxy-WI when it connects to it. Roxy-WI reports bare addresses only, so the
+// network interfaces they belong to are not available.
+func (c *Client) GetServerIPs(ctx context.Context, serverID int) ([]string, error) {
+	var ips []string
+	if err := c.get(ctx, Path("api", "server", serverID, "ip"), &ips); err != nil {
+		return nil, err
+	}
+	for i, ip := range ips {
+		ips[i] = strings.TrimSpace(ip)
+	}
+	return ips, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SSHCredential is a set of credentials Roxy-WI connects to servers with.
+// On creation the passphrase and the private key are left out and set
+// afterwards with UpdateSSHKey.
+type SSHCredential struct {
+	GroupID    Int     `json:"group_id"`
+	KeyEnabled IntBool `json:"key_enabled"`
+	Name       String  `json:"name"`
+	Password   String  `json:"password"`
+	Username   String  `json:"username"`
+	Passphrase String  `json:"passphrase,omitempty"`
+	PrivateKey String  `json:"private_key,omitempty"`
+	Shared     IntBool `json:"shared"`
+}
+
+// SSHKey is the key part of an SSHCredential. Nil fields are left as they
+// are.
+type SSHKey struct {
+	Passphrase *String `json:"passphrase,omitempty"`
+	PrivateKey *String `json:"private_key,omitempty"`
+}
+
+// statusResponse acknowledges a change, e.g. {"id": 1, "status": "Ok"}.
+type statusResponse struct {
+	ID     String `json:"id"`
+	Status String `json:"status"`
+}
+
+// GetSSHCredential returns the credential with the given ID. Depending on
+// the release Roxy-WI returns it as an object or wrapped in a one-element
+// array, so both shapes are accepted.
+func (c *Client) GetSSHCredential(ctx context.Context, id string) (*SSHCredential, error) {
+	endpoint := Path("api", "server", "cred", id)
+	var raw json.RawMessage
+	if err := c.get(ctx, endpoint, &raw); err != nil {
+		return nil, err
+	}
+
+	data := bytes.TrimSpace(raw)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("GET %s: unexpected response format, could not unmarshal: %s", endpoint, raw)
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("GET %s: empty array in response", endpoint)
+		}
+		data = items[0]
+	}
+
+	var cred SSHCredential
+	err := decode(http.MethodGet, endpoint, data, &cred)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &cred, err
+}
+
+// CreateSSHCredential creates cred and returns its ID. The ID is returned
+// with the error if Roxy-WI created the credential but did not acknowledge
+// it with an "Ok" status.
+func (c *Client) CreateSSHCredential(ctx context.Context, cred *SSHCredential) (string, error) {
+	endpoint := Path("api", "server", "cred")
+	var result statusResponse
+	if err := c.call(ctx, http.MethodPost, endpoint, cred, &result); err != nil {
+		return "", err
+	}
+	if result.ID == "" {
+		return "", fmt.Errorf("POST %s: unable to find ID in response", endpoint)
+	}
+	if result.Status != "Ok" {
+		return string(result.ID), fmt.Errorf("POST %s: unexpected status %q in response", endpoint, result.Status)
+	}
+	return string(result.ID), nil
+}
+
+// UpdateSSHCredential replaces the credential with the given ID. It reports
+// whether Roxy-WI acknowledged the update with an "Ok" status, as releases
+// that take the key separately with UpdateSSHKey do. Other releases return
+// the updated credential in an array instead.
+func (c *Client) UpdateSSHCredential(ctx context.Context, id string, cred *SSHCredential) (bool, error) {
+	endpoint := Path("api", "server", "cred", id)
+	var raw json.RawMessage
+	if err := c.call(ctx, http.MethodPut, endpoint, cred, &raw); err != nil {
+		return false, err
+	}
+
+	var status statusResponse
+	if err := json.Unmarshal(raw, &status); err == nil && status.Status == "Ok" {
+		return true, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return false, fmt.Errorf("PUT %s: unexpected response format, could not unmarshal: %s", endpoint, raw)
+	}
+	if len(items) == 0 {
+		return false, fmt.Errorf("PUT %s: empty array in response", endpoint)
+	}
+	return false, nil
+}
+
+// UpdateSSHKey sets the passphrase and the private key of the credential
+// with the given ID.
+func (c *Client) UpdateSSHKey(ctx context.Context, id string, key *SSHKey) error {
+	return c.call(ctx, http.MethodPatch, Path("api", "server", "cred", id), key, nil)
+}
+
+// DeleteSSHCredential deletes the credential with the given ID. Roxy-WI
+// answers with an empty body or with a non-empty array.
+func (c *Client) DeleteSSHCredential(ctx context.Context, id string) error {
+	endpoint := Path("api", "server", "cred", id)
+	resp, err := c.doer.DoRequest(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if len(resp) == 0 {
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(resp, &items); err != nil {
+		return fmt.Errorf("DELETE %s: unexpected response format, could not unmarshal: %s", endpoint, resp)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("DELETE %s: unexpected response format during deletion", endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// respondWith returns a client whose every request is answered with resp.
+func respondWith(resp string) *Client {
+	return New(doerFunc(func(context.Context, string, string, interface{}) ([]byte, error) {
+		return []byte(resp), nil
+	}))
+}
+
+func TestGetSSHCredential(t *testing.T) {
+	tests := []struct {
+		name string
+		resp string
+	}{
+		{name: "object", resp: `{"name": "deploy", "username": "root", "key_enabled": 1}`},
+		{name: "array", resp: `[{"name": "deploy", "username": "root", "key_enabled": 1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := respondWith(tt.resp).GetSSHCredential(context.Background(), "1")
+			if err != nil {
+				t.Fatalf("GetSSHCredential() on %s returned error: %v", tt.resp, err)
+			}
+			if got.Name != "deploy" || got.Username != "root" || !got.KeyEnabled {
+				t.Errorf("GetSSHCredential() on %s = %+v", tt.resp, got)
+			}
+		})
+	}
+}
+
+func TestGetSSHCredentialErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		wantErr string
+	}{
+		{name: "empty array", resp: `[]`, wantErr: "empty array in response"},
+		{name: "null", resp: `null`, wantErr: "empty array in response"},
+		{name: "string", resp: `"Ok"`, wantErr: "unexpected response format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := respondWith(tt.resp).GetSSHCredential(context.Background(), "1")
+			if err == nil {
+				t.Fatalf("GetSSHCredential() on %s succeeded, want error containing %q", tt.resp, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetSSHCredential() on %s error = %q, want it to contain %q", tt.resp, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Roxy-WI is not consistent about JSON types: the same field can be returned
+// as a number, a numeric string, a boolean or null depending on the endpoint
+// and the Roxy-WI version. The types below accept all of those forms.
+
+var jsonNull = []byte("null")
+
+// Int decodes numbers, numeric strings, booleans and null (as 0).
+type Int int
+
+func (i *Int) UnmarshalJSON(data []byte) error {
+	value, err := decodeScalar(data)
+	if err != nil {
+		return err
+	}
+	n, err := ToInt(value)
+	if err != nil {
+		return err
+	}
+	*i = Int(n)
+	return nil
+}
+
+// Bool decodes booleans, 0/1, their string forms and null (as false). It is
+// encoded as a JSON boolean.
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	value, err := decodeScalar(data)
+	if err != nil {
+		return err
+	}
+	v, err := ToBool(value)
+	if err != nil {
+		return err
+	}
+	*b = Bool(v)
+	return nil
+}
+
+// IntBool decodes like Bool, but is encoded as 0 or 1, which is what most
+// Roxy-WI endpoints expect for flags.
+type IntBool bool
+
+func (b *IntBool) UnmarshalJSON(data []byte) error {
+	return (*Bool)(b).UnmarshalJSON(data)
+}
+
+func (b IntBool) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(boolToInt(bool(b)))), nil
+}
+
+// String decodes strings, numbers, booleans and null (as "").
+type String string
+
+func (s *String) UnmarshalJSON(data []byte) error {
+	value, err := decodeScalar(data)
+	if err != nil {
+		return err
+	}
+	v, err := ToString(value)
+	if err != nil {
+		return err
+	}
+	*s = String(v)
+	return nil
+}
+
+// ToInt coerces a value decoded from JSON into an int, with the same rules
+// as Int. A missing value is passed as nil.
+func ToInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		return boolToInt(v), nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %s", v)
+		}
+		return int(f), nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", v)
+		}
+		return int(f), nil
+	}
+	return 0, fmt.Errorf("expected an integer, got %T", value)
+}
+
+// ToBool coerces a value decoded from JSON into a bool, with the same rules
+// as Bool.
+func ToBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case int:
+		return v != 0, nil
+	case int64:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean %s", v)
+		}
+		return f != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "true", "yes", "on":
+			return true, nil
+		case "", "0", "false", "no", "off", "none":
+			return false, nil
+		}
+		return false, fmt.Errorf("invalid boolean %q", v)
+	}
+	return false, fmt.Errorf("expected a boolean, got %T", value)
+}
+
+// ToString coerces a value decoded from JSON into a string, with the same
+// rules as String.
+func ToString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("expected a string, got %T", value)
+}
+
+// ConfigList is a list of objects. Roxy-WI stores some of them as a
+// Python-style string, e.g. "[{'ip': '10.0.0.1', 'port': 80}]", which is
+// decoded as well.
+type ConfigList []map[string]interface{}
+
+func (l *ConfigList) UnmarshalJSON(data []byte) error {
+	var list []map[string]interface{}
+	if err := decodeStringified(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// ConfigMap is an object that may also be returned as a Python-style string.
+type ConfigMap map[string]interface{}
+
+func (m *ConfigMap) UnmarshalJSON(data []byte) error {
+	var object map[string]interface{}
+	if err := decodeStringified(data, &object); err != nil {
+		return err
+	}
+	*m = object
+	return nil
+}
+
+// StringList is a list of strings that may also be returned as a
+// Python-style string, e.g. "['127.0.0.1 local1']".
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var list []interface{}
+	if err := decodeStringified(data, &list); err != nil {
+		return err
+	}
+	if list == nil {
+		return nil
+	}
+
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		s, err := ToString(item)
+		if err != nil {
+			return err
+		}
+		strs = append(strs, s)
+	}
+	*l = strs
+	return nil
+}
+
+// decodeStringified decodes data into out, first unwrapping it if it is a
+// JSON string holding a Python-style literal.
+func decodeStringified(data []byte, out interface{}) error {
+	if bytes.Equal(bytes.TrimSpace(data), jsonNull) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		value, err := ParseLiteral(s)
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func decodeScalar(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	switch value.(type) {
+	case nil, bool, json.Number, string:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("expected a scalar value, got %s", data)
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
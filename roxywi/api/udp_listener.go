@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// UDPListener is a keepalived virtual server balancing UDP traffic between
+// backends.
+type UDPListener struct {
+	ID               Int        `json:"id,omitempty"`
+	ClusterID        Int        `json:"cluster_id"`
+	ServerID         Int        `json:"server_id"`
+	GroupID          Int        `json:"group_id"`
+	Name             String     `json:"name"`
+	Description      String     `json:"description"`
+	VIP              String     `json:"vip"`
+	Port             Int        `json:"port"`
+	LbAlgo           String     `json:"lb_algo"`
+	Config           ConfigList `json:"config"`
+	IsChecker        IntBool    `json:"is_checker"`
+	CheckEnabled     Int        `json:"check_enabled,omitempty"`
+	DelayBeforeRetry Int        `json:"delay_before_retry,omitempty"`
+	DelayLoop        Int        `json:"delay_loop,omitempty"`
+	Retry            Int        `json:"retry,omitempty"`
+	// Reconfigure makes Roxy-WI redeploy keepalived with the listener.
+	Reconfigure bool `json:"reconfigure,omitempty"`
+}
+
+func (c *Client) GetUDPListener(ctx context.Context, id string) (*UDPListener, error) {
+	var listener UDPListener
+	err := c.get(ctx, Path("api", "udp", "listener", id), &listener)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &listener, err
+}
+
+// ListUDPListeners returns every UDP listener the user can see, together
+// with a *DecodeError if some of their fields could not be decoded, see
+// ItemError.
+func (c *Client) ListUDPListeners(ctx context.Context) ([]UDPListener, error) {
+	var listeners []UDPListener
+	err := c.get(ctx, Path("api", "udp", "listeners"), &listeners)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return listeners, err
+}
+
+// CreateUDPListener creates listener and returns its ID.
+func (c *Client) CreateUDPListener(ctx context.Context, listener *UDPListener) (string, error) {
+	return c.create(ctx, Path("api", "udp", "listener"), listener)
+}
+
+func (c *Client) UpdateUDPListener(ctx context.Context, id string, listener *UDPListener) error {
+	return c.call(ctx, http.MethodPut, Path("api", "udp", "listener", id), listener, nil)
+}
+
+func (c *Client) DeleteUDPListener(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "udp", "listener", id), nil, nil)
+}
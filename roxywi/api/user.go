@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// User is a Roxy-WI user. The password is never sent back.
+type User struct {
+	Email    String  `json:"email"`
+	Enabled  IntBool `json:"enabled"`
+	Password String  `json:"password,omitempty"`
+	Username String  `json:"username"`
+}
+
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	err := c.get(ctx, Path("api", "user", id), &user)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return &user, err
+}
+
+// CreateUser creates user and returns its ID.
+func (c *Client) CreateUser(ctx context.Context, user *User) (string, error) {
+	return c.create(ctx, Path("api", "user"), user)
+}
+
+func (c *Client) UpdateUser(ctx context.Context, id string, user *User) error {
+	return c.call(ctx, http.MethodPut, Path("api", "user", id), user, nil)
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "user", id), nil, nil)
+}
+
+// Role is a user role, which grants a user permissions within a group.
+type Role struct {
+	ID          Int    `json:"role_id"`
+	Name        String `json:"name"`
+	Description String `json:"description"`
+}
+
+// ListRoles returns every user role. The roles are returned together with a
+// *DecodeError if some of their fields could not be decoded, see ItemError.
+func (c *Client) ListRoles(ctx context.Context) ([]Role, error) {
+	var roles []Role
+	err := c.get(ctx, Path("api", "user", "roles"), &roles)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return roles, err
+}
+
+// UserGroup is the role a user has in one of their groups.
+type UserGroup struct {
+	GroupID Int `json:"user_group_id"`
+	RoleID  Int `json:"user_role_id"`
+}
+
+// userGroupBinding is the body that adds a user to a group.
+type userGroupBinding struct {
+	RoleID int `json:"role_id"`
+}
+
+// ListUserGroups returns the groups of the user with the given ID, together
+// with a *DecodeError if some of their fields could not be decoded.
+func (c *Client) ListUserGroups(ctx context.Context, userID int) ([]UserGroup, error) {
+	var groups []UserGroup
+	err := c.get(ctx, Path("api", "user", userID, "groups"), &groups)
+	if err != nil && !IsDecodeError(err) {
+		return nil, err
+	}
+	return groups, err
+}
+
+// AddUserGroup adds the user to the group with the given role.
+func (c *Client) AddUserGroup(ctx context.Context, userID, groupID, roleID int) error {
+	return c.call(ctx, http.MethodPost, Path("api", "user", userID, "groups", groupID), &userGroupBinding{RoleID: roleID}, nil)
+}
+
+// UpdateUserGroup changes the role of the user in the group.
+func (c *Client) UpdateUserGroup(ctx context.Context, userID, groupID, roleID int) error {
+	return c.call(ctx, http.MethodPut, Path("api", "user", userID, "groups", groupID), &userGroupBinding{RoleID: roleID}, nil)
+}
+
+// RemoveUserGroup removes the user from the group.
+func (c *Client) RemoveUserGroup(ctx context.Context, userID, groupID int) error {
+	return c.call(ctx, http.MethodDelete, Path("api", "user", userID, "groups", groupID), nil, nil)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GetVersion returns the version string Roxy-WI reports on GET /api/version,
+// e.g. "8.1.2". Releases that do not serve the endpoint answer with an error,
+// which callers treat as an unknown version.
+func (c *Client) GetVersion(ctx context.Context) (string, error) {
+	resp, err := c.doer.DoRequest(ctx, http.MethodGet, Path("api", "version"), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Version String `json:"version"`
+	}
+	if err := json.Unmarshal(resp, &result); err == nil && result.Version != "" {
+		return string(result.Version), nil
+	}
+
+	// Older releases answer with the bare version string.
+	var version string
+	if err := json.Unmarshal(resp, &version); err == nil && version != "" {
+		return version, nil
+	}
+	if version = strings.TrimSpace(string(resp)); version != "" && !strings.ContainsAny(version, "{[") {
+		return version, nil
+	}
+
+	return "", fmt.Errorf("GET /api/version: unable to find version in response: %s", resp)
+}
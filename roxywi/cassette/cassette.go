@@ -0,0 +1,156 @@
+// Package cassette records Roxy-WI API traffic to fixture files and replays
+// it, so the wire behaviour of a given Roxy-WI version can be locked in
+// without a live instance.
+//
+// A Recorder wraps a real transport and collects every request/response
+// pair, with credentials, tokens and keys scrubbed, until Save writes them to
+// a cassette file. A Replayer serves the recorded responses back in order.
+// Both are http.RoundTrippers and plug into roxywi.NewClient with
+// roxywi.WithTransport. They are meant for tests only: the provider itself
+// never installs them.
+//
+// Paths are recorded relative to the base_url the client was configured
+// with, so a cassette recorded against https://host/roxy-wi/ replays against
+// http://127.0.0.1:8080/ as well.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+// Redacted replaces scrubbed values in cassettes.
+const Redacted = "REDACTED"
+
+// Cassette is the content of a fixture file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response Roxy-WI sent to it.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	// Path is the request path and query relative to base_url, e.g.
+	// "api/servers", so that cassettes replay against any base_url.
+	Path string `json:"path"`
+	Body string `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int               `json:"status_code"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// recordedHeaders are the response headers the client acts on. Everything
+// else, cookies in particular, is left out of cassettes.
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Load reads the cassette at name.
+func Load(name string) (*Cassette, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unable to parse cassette %s: %w", name, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to name, replacing it atomically.
+func (c *Cassette) Save(name string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// basePath returns the escaped path of baseURL without trailing slashes,
+// e.g. "/roxy-wi" for https://host/roxy-wi/.
+func basePath(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	return strings.TrimRight(u.EscapedPath(), "/"), nil
+}
+
+// newRequest returns the recorded form of req, whose path must lie under
+// base, as returned by basePath.
+func newRequest(req *http.Request, base string) (Request, error) {
+	p := path.Clean("/" + req.URL.EscapedPath())
+	if !strings.HasPrefix(p, base+"/") {
+		return Request{}, fmt.Errorf("request path %s is outside of the base URL path %s/", p, base)
+	}
+	p = strings.TrimPrefix(p, base+"/")
+
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return Request{}, err
+	}
+
+	if req.URL.RawQuery != "" {
+		p += "?" + req.URL.RawQuery
+	}
+
+	return Request{
+		Method: req.Method,
+		Path:   p,
+		Body:   scrub(body),
+	}, nil
+}
+
+// readBody reads and replaces *body so that it can still be sent or returned.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// scrub replaces the values of sensitive keys in a JSON body, including
+// inside string values holding Python literals. Bodies that are not JSON are
+// kept as they are.
+func scrub(body []byte) string {
+	return api.RedactJSON(body, Redacted)
+}
@@ -0,0 +1,197 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestRoxyWI(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.URL.Path {
+		case "/roxy-wi/api/login":
+			io.WriteString(w, `{"access_token": "eyJ.secret.token"}`)
+		case "/roxy-wi/api/servers":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"error": "slow down"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, rt http.RoundTripper, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestRecorder(t *testing.T) {
+	srv := newTestRoxyWI(t)
+	name := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := NewRecorder(srv.URL+"/roxy-wi/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := do(t, rec, http.MethodPost, srv.URL+"/roxy-wi/api/login", `{"login": "admin", "password": "hunter2"}`)
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "eyJ.secret.token") {
+		t.Errorf("the client got %s, want the real response", body)
+	}
+	do(t, rec, http.MethodGet, srv.URL+"/roxy-wi/api/servers?group=1", "")
+
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("cassette written before Save: %v", err)
+	}
+	if err := rec.Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 2 {
+		t.Fatalf("got %d interactions, want 2", len(c.Interactions))
+	}
+
+	login := c.Interactions[0]
+	if login.Request.Path != "api/login" {
+		t.Errorf("login path = %q, want it relative to the base URL", login.Request.Path)
+	}
+	if login.Request.Body != `{"login":"admin","password":"REDACTED"}` {
+		t.Errorf("login request body = %s, want the password scrubbed", login.Request.Body)
+	}
+	if login.Response.Body != `{"access_token":"REDACTED"}` {
+		t.Errorf("login response body = %s, want the token scrubbed", login.Response.Body)
+	}
+	if _, ok := login.Response.Header["Set-Cookie"]; ok {
+		t.Errorf("cookies were recorded: %v", login.Response.Header)
+	}
+
+	servers := c.Interactions[1]
+	if servers.Request.Path != "api/servers?group=1" {
+		t.Errorf("servers path = %q, want the query kept", servers.Request.Path)
+	}
+	if servers.Response.StatusCode != http.StatusTooManyRequests || servers.Response.Header["Retry-After"] != "3" {
+		t.Errorf("servers response = %+v, want the 429 and its Retry-After", servers.Response)
+	}
+}
+
+func TestRecorderRejectsRequestsOutsideBaseURL(t *testing.T) {
+	srv := newTestRoxyWI(t)
+
+	rec, err := NewRecorder(srv.URL+"/roxy-wi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/roxy-wi-other/api/servers", nil)
+	if _, err := rec.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "outside of the base URL path /roxy-wi/") {
+		t.Errorf("RoundTrip() error = %v, want a base URL error", err)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cassette.json")
+	c := &Cassette{Interactions: []Interaction{
+		{
+			Request:  Request{Method: http.MethodGet, Path: "api/server/1"},
+			Response: Response{StatusCode: http.StatusServiceUnavailable, Header: map[string]string{"Retry-After": "1"}},
+		},
+		{
+			Request:  Request{Method: http.MethodGet, Path: "api/server/1"},
+			Response: Response{StatusCode: http.StatusOK, Body: `{"id": 1}`},
+		},
+		{
+			Request:  Request{Method: http.MethodDelete, Path: "api/server/1"},
+			Response: Response{StatusCode: http.StatusNoContent},
+		},
+	}}
+	if err := c.Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cassette has no base path; it replays under any base URL.
+	replayer, err := NewReplayer(name, "https://roxy.example.com/sub/path/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := do(t, replayer, http.MethodGet, "https://roxy.example.com/sub/path/api/server/1", "")
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("first GET = %d %v, want the recorded 503", resp.StatusCode, resp.Header)
+	}
+	resp = do(t, replayer, http.MethodGet, "https://roxy.example.com/sub/path/api/server/1", "")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `{"id": 1}` {
+		t.Errorf("second GET = %d %s, want the recorded 200", resp.StatusCode, body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://roxy.example.com/sub/path/api/server/1", nil)
+	if _, err := replayer.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "no unused interaction for GET /api/server/1") {
+		t.Errorf("third GET error = %v, want the cassette to be exhausted", err)
+	}
+
+	unused := replayer.Unused()
+	if len(unused) != 1 || unused[0].Request.Method != http.MethodDelete {
+		t.Errorf("Unused() = %+v, want the DELETE", unused)
+	}
+}
+
+func TestScrub(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "JSON key",
+			body: `{"login": "admin", "password": "hunter2"}`,
+			want: `{"login":"admin","password":"REDACTED"}`,
+		},
+		{
+			name: "Python literal in a string",
+			body: `{"userlist_users": "[{'user': 'admin', 'password': 'hunter2'}]"}`,
+			want: `{"userlist_users":"[{\"password\":\"REDACTED\",\"user\":\"admin\"}]"}`,
+		},
+		{
+			name: "unparsable literal",
+			body: `{"userlist_users": "[{'password': 'hunter2'"}`,
+			want: `{"userlist_users":"REDACTED"}`,
+		},
+		{
+			name: "literal without sensitive keys",
+			body: `{"servers": "[{'server': '10.0.0.1'}]"}`,
+			want: `{"servers":"[{'server': '10.0.0.1'}]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrub([]byte(tt.body)); got != tt.want {
+				t.Errorf("scrub(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package cassette
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper that sends requests through Next and
+// keeps every exchange until Save writes them to a cassette file.
+type Recorder struct {
+	// Next performs the real requests; http.DefaultTransport when nil.
+	Next http.RoundTripper
+
+	base     string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder for a client configured with baseURL.
+func NewRecorder(baseURL string, next http.RoundTripper) (*Recorder, error) {
+	base, err := basePath(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Next: next, base: base}, nil
+}
+
+// Save writes the exchanges recorded so far to the cassette name, replacing
+// any existing cassette.
+func (r *Recorder) Save(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(name)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := newRequest(req, r.base)
+	if err != nil {
+		return nil, err
+	}
+
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(map[string]string)
+	for _, key := range recordedHeaders {
+		if value := resp.Header.Get(key); value != "" {
+			header[key] = value
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       scrub(body),
+		},
+	})
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette.
+// Each recorded interaction is served once, in recording order, to the first
+// request with the same method, path and scrubbed body.
+type Replayer struct {
+	name string
+	base string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer loads the cassette name for replay to a client configured
+// with baseURL.
+func NewReplayer(name, baseURL string) (*Replayer, error) {
+	base, err := basePath(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{
+		name:         name,
+		base:         base,
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	incoming, err := newRequest(req, r.base)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request != incoming {
+			continue
+		}
+		r.used[i] = true
+
+		header := make(http.Header)
+		for key, value := range interaction.Response.Header {
+			header.Set(key, value)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette %s has no unused interaction for %s /%s with body %q", r.name, incoming.Method, incoming.Path, incoming.Body)
+}
+
+// Unused returns the recorded interactions that have not been replayed, so
+// tests can assert that the provider made every expected call.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
@@ -2,40 +2,175 @@ package roxywi
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
+// errAPITokenRejected is returned when Roxy-WI does not accept the configured
+// API token.
+var errAPITokenRejected = errors.New("Roxy-WI rejected the API token, check that it is valid and has not been revoked")
+
+// errReadOnly is returned for every request that could change Roxy-WI while
+// the provider is in read-only mode.
+var errReadOnly = errors.New("the provider is configured with `read_only`, refusing to send a request that could change Roxy-WI")
+
+// tokenExpirySkew is how long before the JWT "exp" claim a token is already
+// treated as expired, so that requests do not race the deadline.
+const tokenExpirySkew = 30 * time.Second
+
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	login      string
 	password   string
 	userAgent  string
-	token      string
+	retry      RetryPolicy
+	// apiToken, if set, is used as the bearer token instead of logging in
+	// with login and password.
+	apiToken string
+	// requestTimeout bounds every single HTTP call; zero means no limit
+	// beyond the deadline of the Terraform operation.
+	requestTimeout time.Duration
+	// credentialProcess, if set, is a command that prints the login and
+	// password or the API token. It is run again whenever Roxy-WI rejects the
+	// current token.
+	credentialProcess string
+	// tokenCache, if set, shares session tokens with other provider
+	// processes.
+	tokenCache *tokenCache
+	// readOnly makes the client refuse every request other than GET, except
+	// for logging in.
+	readOnly bool
+	// optErr records the first error raised by a ClientOption.
+	optErr error
+
+	// authMu guards token and tokenExpiry. Re-authentication takes the write
+	// lock, so concurrent resource operations wait for a single login.
+	authMu      sync.RWMutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// ClientOption customizes a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithRetryPolicy sets the policy used to retry transient API failures.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithAPIToken authenticates with a Roxy-WI API token instead of login and
+// password.
+func WithAPIToken(token string) ClientOption {
+	return func(c *Client) {
+		c.apiToken = token
+	}
+}
+
+// WithRequestTimeout limits the duration of every single HTTP call.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
 }
 
-func NewClient(baseURL, login, password, userAgent string) (*Client, error) {
+// WithCredentialProcess obtains the credentials from the output of command
+// instead of from login and password.
+func WithCredentialProcess(command string) ClientOption {
+	return func(c *Client) {
+		c.credentialProcess = command
+	}
+}
+
+// WithTokenCache caches session tokens in dir, so that a valid token is
+// reused instead of logging in again or running the credential process. It
+// has no effect with an API token.
+func WithTokenCache(dir string) ClientOption {
+	return func(c *Client) {
+		c.tokenCache = newTokenCache(dir)
+	}
+}
+
+// WithReadOnly makes the client refuse every request that is not a GET.
+func WithReadOnly(readOnly bool) ClientOption {
+	return func(c *Client) {
+		c.readOnly = readOnly
+	}
+}
+
+// WithTransport sets the transport used for all HTTP calls, e.g. a cassette
+// recorder or replayer. It replaces the transport set by WithTLSSettings, so
+// TLS settings must be applied to rt by the caller.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
+
+func NewClient(ctx context.Context, baseURL, login, password, userAgent string, opts ...ClientOption) (*Client, error) {
 	client := &Client{
 		baseURL:    baseURL,
 		httpClient: &http.Client{},
 		login:      login,
 		password:   password,
 		userAgent:  userAgent,
+		retry:      defaultRetryPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+	if client.optErr != nil {
+		return nil, client.optErr
 	}
 
-	if err := client.authenticate(); err != nil {
+	if client.apiToken != "" && client.credentialProcess == "" {
+		client.token = client.apiToken
+		client.tokenExpiry = jwtExpiry(client.apiToken)
+		return client, nil
+	}
+
+	if client.tokenCache != nil && client.tokenCacheKey() != "" {
+		entry, ok, err := client.tokenCache.Load(client.baseURL, client.tokenCacheKey())
+		if err != nil {
+			tflog.Warn(ctx, "Ignoring the cached Roxy-WI token", map[string]interface{}{"error": err.Error()})
+		}
+		if ok {
+			tflog.Debug(ctx, "Reusing cached Roxy-WI token")
+			client.token = entry.Token
+			client.tokenExpiry = entry.ExpiresAt
+			return client, nil
+		}
+	}
+
+	if _, err := client.refreshToken(withLogMasking(ctx), ""); err != nil {
 		return nil, err
 	}
 
 	return client, nil
 }
 
-func (c *Client) authenticate() error {
-	authURL := fmt.Sprintf("%s/api/login", c.baseURL) // Проверьте, что этот URL корректен
+// authenticate logs in to Roxy-WI and stores the new token. Callers must hold
+// authMu for writing.
+func (c *Client) authenticate(ctx context.Context) error {
 	authData := map[string]string{
 		"login":    c.login,
 		"password": c.password,
@@ -46,32 +181,13 @@ func (c *Client) authenticate() error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", authURL, bytes.NewBuffer(reqBody))
+	statusCode, respBody, err := c.send(ctx, "POST", api.Path("api", "login"), reqBody, "")
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", c.userAgent)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	// Логирование заголовков и тела ответа для диагностики
-	log.Printf("Authentication response status: %s", resp.Status)
-	log.Printf("Authentication response headers: %v", resp.Header)
-	log.Printf("Authentication response body: %s", respBody)
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, respBody)
+	if statusCode != http.StatusOK {
+		return newHTTPError("POST", api.Path("api", "login"), statusCode, respBody)
 	}
 
 	var result map[string]interface{}
@@ -85,11 +201,93 @@ func (c *Client) authenticate() error {
 	}
 
 	c.token = token
+	c.tokenExpiry = jwtExpiry(token)
+	c.cacheToken(ctx)
 	return nil
 }
 
-func (c *Client) doRequest(method, endpoint string, body interface{}) ([]byte, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, endpoint)
+// tokenCacheKey identifies the credentials of the client in the token cache:
+// the credential process that provides them, or else the login.
+func (c *Client) tokenCacheKey() string {
+	if c.credentialProcess != "" {
+		return "credential_process\x00" + c.credentialProcess
+	}
+	return c.login
+}
+
+// cacheToken stores the current token in the token cache, if there is one.
+// Callers must hold authMu for writing.
+func (c *Client) cacheToken(ctx context.Context) {
+	if c.tokenCache == nil {
+		return
+	}
+	if err := c.tokenCache.Store(c.baseURL, c.tokenCacheKey(), c.token, c.tokenExpiry); err != nil {
+		tflog.Warn(ctx, "Unable to cache the Roxy-WI token", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// currentToken returns the token to use for the next request and whether it
+// is already known to be expired.
+func (c *Client) currentToken() (string, bool) {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+
+	expired := !c.tokenExpiry.IsZero() && time.Now().Add(tokenExpirySkew).After(c.tokenExpiry)
+	return c.token, expired
+}
+
+// refreshToken logs in again and returns the new token. If another request
+// already replaced staleToken while we were waiting for the lock, that token
+// is reused instead of logging in a second time. With a credential process,
+// the credentials are fetched anew first. A static API token cannot be
+// refreshed, so it is reported as rejected instead.
+func (c *Client) refreshToken(ctx context.Context, staleToken string) (string, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.token != "" && c.token != staleToken {
+		return c.token, nil
+	}
+
+	if c.credentialProcess != "" {
+		creds, err := runCredentialProcess(ctx, c.credentialProcess)
+		if err != nil {
+			return "", err
+		}
+		c.apiToken, c.login, c.password = creds.Token, creds.Login, creds.Password
+		if c.apiToken != "" {
+			if c.apiToken == staleToken {
+				return "", errAPITokenRejected
+			}
+			c.token = c.apiToken
+			c.tokenExpiry = jwtExpiry(c.apiToken)
+			c.cacheToken(ctx)
+			return c.token, nil
+		}
+	}
+
+	if c.apiToken != "" {
+		return "", errAPITokenRejected
+	}
+
+	if err := c.authenticate(ctx); err != nil {
+		return "", err
+	}
+
+	return c.token, nil
+}
+
+// DoRequest implements api.Doer.
+func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	return c.doRequest(ctx, method, endpoint, body)
+}
+
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	if c.readOnly && method != http.MethodGet {
+		return nil, fmt.Errorf("%s %s: %w", method, endpoint, errReadOnly)
+	}
+
+	ctx = withLogMasking(ctx)
 
 	var reqBody []byte
 	var err error
@@ -100,29 +298,231 @@ func (c *Client) doRequest(method, endpoint string, body interface{}) ([]byte, e
 		}
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
+	token, expired := c.currentToken()
+	if expired {
+		token, err = c.refreshToken(ctx, token)
+		if errors.Is(err, errAPITokenRejected) {
+			return nil, fmt.Errorf("the API token has expired, please issue a new one")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to refresh expired token: %w", err)
+		}
+	}
+
+	statusCode, respBody, err := c.send(ctx, method, endpoint, reqBody, token)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	if statusCode == http.StatusUnauthorized {
+		token, err = c.refreshToken(ctx, token)
+		if errors.Is(err, errAPITokenRejected) {
+			return nil, fmt.Errorf("%w: %v", errAPITokenRejected, newHTTPError(method, endpoint, statusCode, respBody))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to re-authenticate after status code %d: %w", statusCode, err)
+		}
+
+		statusCode, respBody, err = c.send(ctx, method, endpoint, reqBody, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if statusCode < 200 || statusCode >= 300 {
+		return nil, newHTTPError(method, endpoint, statusCode, respBody)
+	}
+
+	return respBody, nil
+}
+
+// send performs an HTTP call, retrying transient failures according to the
+// client's RetryPolicy. An empty token sends the request unauthenticated.
+func (c *Client) send(ctx context.Context, method, endpoint string, reqBody []byte, token string) (statusCode int, respBody []byte, err error) {
+	ctx, span := tracer().Start(ctx, method+" "+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("url.path", endpoint),
+	))
+	defer func() {
+		if statusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if statusCode >= 400 {
+			span.SetStatus(codes.Error, http.StatusText(statusCode))
+		}
+		span.End()
+	}()
+
+	for attempt := 0; ; attempt++ {
+		span.SetAttributes(attribute.Int("roxywi.retry_count", attempt))
+
+		var header http.Header
+		statusCode, header, respBody, err = c.sendOnce(ctx, method, endpoint, reqBody, token)
+		if ctx.Err() != nil {
+			return 0, nil, fmt.Errorf("%s %s: %w", method, endpoint, ctx.Err())
+		}
+		if attempt >= c.retry.MaxRetries || !c.retry.shouldRetry(method, statusCode, err) {
+			return statusCode, respBody, err
+		}
+
+		wait := c.retry.backoff(attempt, header)
+		fields := map[string]interface{}{
+			"method":      method,
+			"endpoint":    endpoint,
+			"attempt":     attempt + 1,
+			"max_retries": c.retry.MaxRetries,
+			"wait":        wait.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = statusCode
+		}
+		tflog.Debug(ctx, "Retrying Roxy-WI API request", fields)
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("roxywi.attempt", attempt+1),
+			attribute.String("roxywi.wait", wait.String()),
+		))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, nil, fmt.Errorf("%s %s: %w", method, endpoint, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// sendOnce performs a single HTTP call. reqBody is wrapped in a fresh reader
+// on every call so that the same payload can be replayed.
+func (c *Client) sendOnce(ctx context.Context, method, endpoint string, reqBody []byte, token string) (int, http.Header, []byte, error) {
+	url := api.JoinURL(c.baseURL, endpoint)
+	logFields := map[string]interface{}{
+		"method":   method,
+		"endpoint": endpoint,
+	}
+
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", c.userAgent)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	tflog.Debug(ctx, "Sending Roxy-WI API request", logFields)
+	tflog.Trace(ctx, "Roxy-WI API request body", withField(logFields, "body", redactBody(reqBody)))
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		tflog.Debug(ctx, "Roxy-WI API request failed", withField(logFields, "error", err.Error()))
+		return 0, nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return 0, nil, nil, err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, respBody)
+	logFields["status"] = resp.StatusCode
+	logFields["duration_ms"] = time.Since(start).Milliseconds()
+	tflog.Debug(ctx, "Received Roxy-WI API response", logFields)
+	tflog.Trace(ctx, "Roxy-WI API response body", withField(logFields, "body", redactBody(respBody)))
+
+	return resp.StatusCode, resp.Header, respBody, nil
+}
+
+// jwtExpiry returns the "exp" claim of a JWT, or the zero time if the token
+// is not a JWT or carries no expiry.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
 	}
 
-	return respBody, nil
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
+
+// httpError is returned by doRequest when Roxy-WI answers with a non-2xx
+// status code.
+type httpError struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	// Message is the error reported by Roxy-WI, or the raw response body if
+	// it could not be extracted.
+	Message string
+}
+
+func newHTTPError(method, endpoint string, statusCode int, respBody []byte) *httpError {
+	return &httpError{
+		Method:     method,
+		Endpoint:   endpoint,
+		StatusCode: statusCode,
+		Message:    apiErrorMessage(respBody),
+	}
+}
+
+func (e *httpError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: unexpected status code: %d", e.Method, e.Endpoint, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: unexpected status code: %d: %s", e.Method, e.Endpoint, e.StatusCode, e.Message)
+}
+
+// HTTPStatusCode lets api.IsNotFound inspect the error.
+func (e *httpError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// apiErrorMessage extracts the error text from a Roxy-WI error response.
+// Roxy-WI reports errors as {"status": "failed", "error": "..."}, while the
+// JWT layer uses {"msg": "..."}.
+func apiErrorMessage(respBody []byte) string {
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err == nil {
+		for _, key := range []string{"error", "message", "msg"} {
+			if message, ok := result[key].(string); ok && message != "" {
+				return message
+			}
+		}
+	}
+	return strings.TrimSpace(string(respBody))
+}
+
+// isNotFound reports whether err is a 404 response from Roxy-WI.
+func isNotFound(err error) bool {
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusNotFound
+	}
+	return false
 }
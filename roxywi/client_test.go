@@ -0,0 +1,241 @@
+package roxywi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+const testGroupConfig = `
+resource "roxywi_group" "test" {
+  name        = "web"
+  description = "Web servers"
+}
+`
+
+// requestsTo returns the indexes in srv.Requests() of the requests with
+// method whose path matches pattern.
+func requestsTo(srv *roxywitest.Server, method, pattern string) []int {
+	re := regexp.MustCompile(pattern)
+	var indexes []int
+	for i, req := range srv.Requests() {
+		if req.Method == method && re.MatchString(req.Path) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func TestFaultServerErrorIsNotRetried(t *testing.T) {
+	srv := newTestServer(t)
+	srv.InjectFault(roxywitest.ServerError(http.MethodPost, `^api/group$`, 1))
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_group", idPath("api/group")),
+		Steps: []resource.TestStep{
+			{
+				Config:      testProviderConfig(srv) + testGroupConfig,
+				ExpectError: regexp.MustCompile(`POST /api/group: unexpected status code: 500`),
+			},
+			{
+				// The fault only applied once, so the next apply goes through.
+				Config: testProviderConfig(srv) + testGroupConfig,
+				Check: func(*terraform.State) error {
+					if n := len(requestsTo(srv, http.MethodPost, `^api/group$`)); n != 2 {
+						return fmt.Errorf("got %d create requests, want one per apply", n)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestFaultServiceUnavailableIsRetried(t *testing.T) {
+	srv := newTestServer(t)
+	srv.InjectFault(roxywitest.Fault{
+		Method:     http.MethodPost,
+		Path:       `^api/group$`,
+		StatusCode: http.StatusServiceUnavailable,
+		Times:      2,
+	})
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_group", idPath("api/group")),
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + testGroupConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_group.test", idPath("api/group")),
+					func(*terraform.State) error {
+						if n := len(requestsTo(srv, http.MethodPost, `^api/group$`)); n != 3 {
+							return fmt.Errorf("got %d create requests, want 2 failed attempts and 1 success", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestFaultTooManyRequestsHonoursRetryAfter(t *testing.T) {
+	srv := newTestServer(t)
+	srv.InjectFault(roxywitest.TooManyRequests(http.MethodGet, `^api/group/\d+$`, time.Second, 1))
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_group", idPath("api/group")),
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + testGroupConfig,
+				Check: func(*terraform.State) error {
+					reads := requestsTo(srv, http.MethodGet, `^api/group/\d+$`)
+					if len(reads) < 2 {
+						return fmt.Errorf("got %d reads, want the throttled read to be retried", len(reads))
+					}
+					requests := srv.Requests()
+					if wait := requests[reads[1]].Time.Sub(requests[reads[0]].Time); wait < 900*time.Millisecond {
+						return fmt.Errorf("retried after %s, want the 1s asked for by Retry-After", wait)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestFaultExpiredTokenLogsInAgain(t *testing.T) {
+	srv := newTestServer(t)
+	srv.InjectFault(roxywitest.Unauthorized(http.MethodGet, `^api/group/\d+$`, 1))
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_group", idPath("api/group")),
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + testGroupConfig,
+				Check: func(*terraform.State) error {
+					requests := srv.Requests()
+					reads := requestsTo(srv, http.MethodGet, `^api/group/\d+$`)
+					if len(reads) < 2 || reads[1] != reads[0]+2 || requests[reads[0]+1].Path != "api/login" {
+						return fmt.Errorf("want the rejected read to be followed by a login and a retried read, got requests %v", requests)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestFaultAPITokenRejected(t *testing.T) {
+	srv := newTestServer(t, roxywitest.WithAPIToken("static-token"))
+	srv.InjectFault(roxywitest.Unauthorized(http.MethodPost, `^api/group$`, 0))
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "roxywi" {
+  base_url  = %q
+  api_token = "static-token"
+}
+`, srv.URL+"/") + testGroupConfig,
+				ExpectError: regexp.MustCompile(`Roxy-WI rejected the API token`),
+			},
+		},
+	})
+
+	if n := len(requestsTo(srv, http.MethodPost, `^api/login$`)); n != 0 {
+		t.Errorf("got %d logins, want none with an API token", n)
+	}
+}
+
+func TestReadOnlySendsOnlyReads(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/group/7", map[string]interface{}{"group_id": 7, "name": "web", "description": "Web servers"})
+
+	config := fmt.Sprintf(`
+provider "roxywi" {
+  base_url       = %q
+  login          = %q
+  password       = %q
+  read_only      = true
+  retry_min_wait = 0
+  retry_max_wait = 1
+}
+
+data "roxywi_group" "web" {
+  id = "7"
+}
+`, srv.URL+"/", roxywitest.DefaultLogin, roxywitest.DefaultPassword) + testGroupConfig
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta(errReadOnly.Error())),
+			},
+		},
+	})
+
+	var reads int
+	for _, req := range srv.Requests() {
+		switch {
+		case req.Method == http.MethodGet:
+			reads++
+		case req.Method == http.MethodPost && req.Path == "api/login":
+		default:
+			t.Errorf("read-only provider sent %s %s", req.Method, req.Path)
+		}
+	}
+	if reads == 0 {
+		t.Error("read-only provider sent no GET request, want the data source to be read")
+	}
+}
+
+func TestFaultSlowResponseTimesOut(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/group/7", map[string]interface{}{"group_id": 7, "name": "web"})
+	srv.InjectFault(roxywitest.Slow(http.MethodGet, `^api/group/7$`, 10*time.Second))
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "roxywi" {
+  base_url        = %q
+  login           = %q
+  password        = %q
+  request_timeout = 1
+  max_retries     = 2
+  retry_min_wait  = 0
+  retry_max_wait  = 1
+}
+
+data "roxywi_group" "web" {
+  id = "7"
+}
+`, srv.URL+"/", roxywitest.DefaultLogin, roxywitest.DefaultPassword),
+				ExpectError: regexp.MustCompile(`context deadline exceeded`),
+			},
+		},
+	})
+
+	// Every attempt gets its own timeout, and the read gives up after the
+	// first try and max_retries retries.
+	if got := len(requestsTo(srv, http.MethodGet, `^api/group/7$`)); got != 3 {
+		t.Errorf("got %d requests for the slow group, want 3", got)
+	}
+}
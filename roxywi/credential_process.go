@@ -0,0 +1,57 @@
+package roxywi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// processCredentials is the JSON a credential process prints on stdout:
+// either a login and password, or an API token.
+type processCredentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// runCredentialProcess runs command through the system shell and parses the
+// credentials it prints. Its stderr is included in the error if it fails, so
+// that the helper can explain what went wrong.
+func runCredentialProcess(ctx context.Context, command string) (processCredentials, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return processCredentials{}, fmt.Errorf("credential process failed: %w: %s", err, msg)
+		}
+		return processCredentials{}, fmt.Errorf("credential process failed: %w", err)
+	}
+
+	var creds processCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		// The output is not echoed, as it is likely to contain secrets.
+		return processCredentials{}, fmt.Errorf("credential process printed invalid JSON: %v", err)
+	}
+
+	switch {
+	case creds.Token != "" && (creds.Login != "" || creds.Password != ""):
+		return processCredentials{}, fmt.Errorf("credential process must print either `token` or `login` and `password`, not both")
+	case creds.Token == "" && (creds.Login == "" || creds.Password == ""):
+		return processCredentials{}, fmt.Errorf("credential process must print either `token` or both `login` and `password`")
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,197 @@
+package roxywi
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+// skipWithoutShell skips tests whose credential processes are POSIX shell
+// commands.
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the credential processes below are POSIX shell commands")
+	}
+}
+
+func TestRunCredentialProcess(t *testing.T) {
+	skipWithoutShell(t)
+
+	tests := []struct {
+		name    string
+		command string
+		want    processCredentials
+	}{
+		{
+			name:    "login and password",
+			command: `echo '{"login": "admin", "password": "secret"}'`,
+			want:    processCredentials{Login: "admin", Password: "secret"},
+		},
+		{
+			name:    "token",
+			command: `echo '{"token": "abc"}'`,
+			want:    processCredentials{Token: "abc"},
+		},
+		{
+			name:    "stderr is ignored on success",
+			command: `echo 'fetching from vault' >&2; echo '{"token": "abc"}'`,
+			want:    processCredentials{Token: "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCredentialProcess(context.Background(), tt.command)
+			if err != nil {
+				t.Fatalf("runCredentialProcess(%q) returned error: %v", tt.command, err)
+			}
+			if got != tt.want {
+				t.Errorf("runCredentialProcess(%q) = %+v, want %+v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCredentialProcessErrors(t *testing.T) {
+	skipWithoutShell(t)
+
+	tests := []struct {
+		name    string
+		command string
+		wantErr []string
+		// notInErr must not appear in the error, e.g. secrets printed by the
+		// process.
+		notInErr string
+	}{
+		{
+			name:     "invalid JSON",
+			command:  `echo 'password=hunter2'`,
+			wantErr:  []string{"credential process printed invalid JSON"},
+			notInErr: "hunter2",
+		},
+		{
+			name:     "token and login",
+			command:  `echo '{"token": "abc", "login": "admin", "password": "hunter2"}'`,
+			wantErr:  []string{"either `token` or `login` and `password`, not both"},
+			notInErr: "hunter2",
+		},
+		{
+			name:    "login without password",
+			command: `echo '{"login": "admin"}'`,
+			wantErr: []string{"either `token` or both `login` and `password`"},
+		},
+		{
+			name:    "non-zero exit with stderr",
+			command: `echo '{"token": "abc"}'; echo 'vault is sealed' >&2; exit 3`,
+			wantErr: []string{"credential process failed", "exit status 3", "vault is sealed"},
+		},
+		{
+			name:    "non-zero exit without stderr",
+			command: `exit 2`,
+			wantErr: []string{"credential process failed: exit status 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCredentialProcess(context.Background(), tt.command)
+			if err == nil {
+				t.Fatalf("runCredentialProcess(%q) succeeded, want an error", tt.command)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("runCredentialProcess(%q) error = %q, want it to contain %q", tt.command, err, want)
+				}
+			}
+			if tt.notInErr != "" && strings.Contains(err.Error(), tt.notInErr) {
+				t.Errorf("runCredentialProcess(%q) error = %q, which leaks %q", tt.command, err, tt.notInErr)
+			}
+		})
+	}
+}
+
+// countRuns returns how many times a credential process that appends a line
+// to runs has been started.
+func countRuns(t *testing.T, runs string) int {
+	t.Helper()
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "run")
+}
+
+// TestCredentialProcessRunsAgainAfterUnauthorized checks that a token
+// rejected partway through a run makes the client ask the credential process
+// for fresh credentials and log in with them.
+func TestCredentialProcessRunsAgainAfterUnauthorized(t *testing.T) {
+	skipWithoutShell(t)
+
+	srv := newTestServer(t)
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := `echo run >> ` + runs + `; echo '{"login": "` + roxywitest.DefaultLogin + `", "password": "` + roxywitest.DefaultPassword + `"}'`
+
+	client, err := NewClient(context.Background(), srv.URL+"/", "", "", "test", WithCredentialProcess(command))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.doRequest(context.Background(), http.MethodGet, "/api/groups", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRuns(t, runs); n != 1 {
+		t.Fatalf("the credential process ran %d times before the 401, want once", n)
+	}
+
+	srv.InjectFault(roxywitest.Unauthorized(http.MethodGet, `^api/groups$`, 1))
+	if _, err := client.doRequest(context.Background(), http.MethodGet, "/api/groups", nil); err != nil {
+		t.Fatalf("request after a 401 failed: %v", err)
+	}
+
+	if n := countRuns(t, runs); n != 2 {
+		t.Errorf("the credential process ran %d times, want it to run again after the 401", n)
+	}
+	if n := len(requestsTo(srv, http.MethodPost, `^api/login$`)); n != 2 {
+		t.Errorf("got %d logins, want a new login with the fresh credentials", n)
+	}
+}
+
+// TestCredentialProcessRotatesTokenAfterUnauthorized checks the same for a
+// credential process printing API tokens: the token it prints after the 401
+// replaces the rejected one.
+func TestCredentialProcessRotatesTokenAfterUnauthorized(t *testing.T) {
+	skipWithoutShell(t)
+
+	srv := newTestServer(t, roxywitest.WithAPIToken("first"), roxywitest.WithAPIToken("second"))
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := `echo run >> ` + runs + `; if [ "$(grep -c run ` + runs + `)" -eq 1 ]; then echo '{"token": "first"}'; else echo '{"token": "second"}'; fi`
+
+	client, err := NewClient(context.Background(), srv.URL+"/", "", "", "test", WithCredentialProcess(command))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.doRequest(context.Background(), http.MethodGet, "/api/groups", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.InjectFault(roxywitest.Unauthorized(http.MethodGet, `^api/groups$`, 1))
+	if _, err := client.doRequest(context.Background(), http.MethodGet, "/api/groups", nil); err != nil {
+		t.Fatalf("request after a 401 failed: %v", err)
+	}
+
+	if n := countRuns(t, runs); n != 2 {
+		t.Errorf("the credential process ran %d times, want it to run again after the 401", n)
+	}
+	if token, _ := client.currentToken(); token != "second" {
+		t.Errorf("client uses token %q after the 401, want the rotated token", token)
+	}
+	if n := len(requestsTo(srv, http.MethodPost, `^api/login$`)); n != 0 {
+		t.Errorf("got %d logins, want the API tokens to be used as they are", n)
+	}
+}
@@ -2,11 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -45,7 +45,7 @@ func dataSourceGroup() *schema.Resource {
 }
 
 func dataSourceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	if id, ok := d.GetOk(IDField); ok {
 		return readGroupByID(ctx, d, client, id.(string))
 	} else if name, ok := d.GetOk(NameField); ok {
@@ -55,55 +55,38 @@ func dataSourceGroupRead(ctx context.Context, d *schema.ResourceData, m interfac
 	return diag.Errorf("either 'id' or 'name' must be specified")
 }
 
-func readGroupByID(ctx context.Context, d *schema.ResourceData, client *Client, id string) diag.Diagnostics {
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/group/%s", id), nil)
-	if err != nil {
+func readGroupByID(ctx context.Context, d *schema.ResourceData, client *api.Client, id string) diag.Diagnostics {
+	group, err := client.GetGroup(ctx, id)
+	if err != nil && group == nil {
 		return diag.FromErr(err)
 	}
 
-	// Process response and set data
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	if name, ok := result[NameField].(string); ok {
-		d.Set(NameField, name)
-	}
-
-	if description, ok := result[DescriptionField].(string); ok {
-		d.Set(DescriptionField, description)
-	}
+	d.Set(NameField, string(group.Name))
+	d.Set(DescriptionField, string(group.Description))
 
 	d.SetId(id)
-	return nil
+	return apiDiagnostics(err)
 }
 
-func readGroupByName(ctx context.Context, d *schema.ResourceData, client *Client, name string) diag.Diagnostics {
-	resp, err := client.doRequest("GET", "/api/groups", nil)
-	if err != nil {
+func readGroupByName(ctx context.Context, d *schema.ResourceData, client *api.Client, name string) diag.Diagnostics {
+	groups, err := client.ListGroups(ctx)
+	if err != nil && !api.IsDecodeError(err) {
 		return diag.FromErr(err)
 	}
 
-	// Process response and find the group by name
-	var groups []map[string]interface{}
-	if err := json.Unmarshal(resp, &groups); err != nil {
-		return diag.FromErr(err)
-	}
-
-	for _, group := range groups {
-		if groupName, ok := group[NameField].(string); ok && groupName == name {
-			if id, ok := group["group_id"].(float64); ok {
-				d.SetId(fmt.Sprintf("%d", int(id)))
-				d.Set(NameField, groupName)
-
-				if description, ok := group[DescriptionField].(string); ok {
-					d.Set(DescriptionField, description)
-				}
+	for i, group := range groups {
+		if string(group.Name) != name {
+			continue
+		}
 
-				return nil
-			}
+		d.Set(NameField, string(group.Name))
+		d.Set(DescriptionField, string(group.Description))
+		if diags := apiDiagnostics(api.ItemError(err, i)); diags.HasError() {
+			return diags
 		}
+
+		d.SetId(strconv.Itoa(int(group.ID)))
+		return nil
 	}
 
 	return diag.Errorf("group with name '%s' not found", name)
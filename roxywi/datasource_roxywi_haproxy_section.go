@@ -0,0 +1,119 @@
+package roxywi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceHaproxySectionBackend() *schema.Resource {
+	return dataSourceHaproxySection(resourceHaproxySectionBackend(), "backend",
+		"Reads an HAProxy Backend section, e.g. one managed by another team, without managing it.")
+}
+
+func dataSourceHaproxySectionFrontend() *schema.Resource {
+	return dataSourceHaproxySection(resourceHaproxySectionFrontend(), "frontend",
+		"Reads an HAProxy Frontend section without managing it.")
+}
+
+func dataSourceHaproxySectionListen() *schema.Resource {
+	return dataSourceHaproxySection(resourceHaproxySectionListen(), "listen",
+		"Reads an HAProxy Listen section without managing it.")
+}
+
+func dataSourceHaproxySectionDefaults() *schema.Resource {
+	return dataSourceHaproxySection(resourceHaproxySectionDefaults(), "defaults",
+		"Reads the HAProxy Defaults section of a server without managing it.")
+}
+
+func dataSourceHaproxySectionGlobal() *schema.Resource {
+	return dataSourceHaproxySection(resourceHaproxySectionGlobal(), "global",
+		"Reads the HAProxy Global section of a server without managing it.")
+}
+
+// dataSourceHaproxySection turns the resource of an HAProxy section into a
+// data source with the same attributes. The section is looked up by
+// server_id and, for sections that can exist more than once, by name; the
+// resource's Read function fills in the rest. Defaults and global sections
+// exist once per server and are named after their type.
+func dataSourceHaproxySection(resource *schema.Resource, sectionType, description string) *schema.Resource {
+	_, named := resource.Schema[NameField]
+	keys := []string{ServerIdField}
+	if named {
+		keys = append(keys, NameField)
+	}
+
+	read := func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		serverID := d.Get(ServerIdField).(int)
+		name := sectionType
+		if named {
+			name = d.Get(NameField).(string)
+		}
+
+		d.SetId(fmt.Sprintf("%d-%s", serverID, name))
+		diags := resource.ReadContext(ctx, d, m)
+		if diags.HasError() {
+			return diags
+		}
+		if d.Id() == "" {
+			return append(diags, diag.Errorf("HAProxy %s section '%s' not found on server %d", sectionType, name, serverID)...)
+		}
+
+		// Not every Roxy-WI release echoes the keys back.
+		d.Set(ServerIdField, serverID)
+		if named {
+			d.Set(NameField, name)
+		}
+		return diags
+	}
+
+	return &schema.Resource{
+		ReadContext: read,
+		Description: description,
+		Schema:      dataSourceSchemaFromResource(resource.Schema, keys...),
+	}
+}
+
+// dataSourceSchemaFromResource returns a copy of a resource schema in which
+// the keys are required and every other attribute, nested ones included, is
+// computed.
+func dataSourceSchemaFromResource(resourceSchema map[string]*schema.Schema, keys ...string) map[string]*schema.Schema {
+	result := make(map[string]*schema.Schema, len(resourceSchema))
+	for field, s := range resourceSchema {
+		result[field] = computedSchema(s)
+	}
+
+	for _, key := range keys {
+		s := result[key]
+		s.Computed = false
+		s.Required = true
+		s.ValidateFunc = resourceSchema[key].ValidateFunc
+	}
+	return result
+}
+
+func computedSchema(s *schema.Schema) *schema.Schema {
+	return &schema.Schema{
+		Type:        s.Type,
+		Computed:    true,
+		Description: s.Description,
+		Sensitive:   s.Sensitive,
+		Set:         s.Set,
+		Elem:        computedElem(s.Elem),
+	}
+}
+
+func computedElem(elem interface{}) interface{} {
+	r, ok := elem.(*schema.Resource)
+	if !ok {
+		return elem
+	}
+
+	nested := make(map[string]*schema.Schema, len(r.Schema))
+	for field, s := range r.Schema {
+		nested[field] = computedSchema(s)
+	}
+	return &schema.Resource{Schema: nested}
+}
@@ -0,0 +1,71 @@
+package roxywi
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestDataSourceHaproxySections(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/service/haproxy/1/section/backend/web", map[string]interface{}{
+		"name": "web", "server_id": 1, "balance": "roundrobin", "mode": "http",
+		"backend_servers": []interface{}{map[string]interface{}{"server": "10.0.0.20", "port": 8080, "port_check": 8080, "maxconn": 2000}},
+	})
+	srv.Set("api/service/haproxy/1/section/backend/api", map[string]interface{}{"name": "api", "server_id": 1, "balance": "leastconn"})
+	srv.Set("api/service/haproxy/1/section/frontend/web", map[string]interface{}{"name": "web", "server_id": 1})
+	srv.Set("api/service/haproxy/1/section/global", map[string]interface{}{"server_id": 1, "maxconn": 4000})
+	// Some releases list sections by name only.
+	srv.Handle(http.MethodGet, `^api/service/haproxy/1/section/userlist$`, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["admins"]`))
+	})
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_haproxy_sections" "test" {
+  server_id = 1
+}
+
+data "roxywi_haproxy_section_backend" "test" {
+  server_id = 1
+  name      = "web"
+}
+
+data "roxywi_haproxy_section_global" "test" {
+  server_id = 1
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.#", "4"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.0.type", "frontend"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.0.name", "web"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.1.type", "backend"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.1.name", "api"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.2.name", "web"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.3.type", "userlist"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_sections.test", "sections.3.name", "admins"),
+
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_section_backend.test", "id", "1-web"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_section_backend.test", "balance", "roundrobin"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_section_backend.test", "backend_servers.0.server", "10.0.0.20"),
+					resource.TestCheckResourceAttr("data.roxywi_haproxy_section_global.test", "maxconn", "4000"),
+				),
+			},
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_haproxy_section_listen" "test" {
+  server_id = 1
+  name      = "stats"
+}
+`,
+				ExpectError: regexp.MustCompile(`HAProxy listen section 'stats' not found on server 1`),
+			},
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package roxywi
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+const SectionsField = "sections"
+
+func dataSourceHaproxySections() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceHaproxySectionsRead,
+		Description: "Lists the frontend, backend, listen, peers and userlist sections in the HAProxy config of a server, e.g. to `for_each` over existing backends or to find sections that are not managed by Terraform.",
+
+		Schema: map[string]*schema.Schema{
+			ServerIdField: {
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "ID of the server.",
+			},
+			SectionsField: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The sections: frontends first, then backends, listens, peers and userlists, each ordered by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						TypeField: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the section: `frontend`, `backend`, `listen`, `peers` or `userlist`.",
+						},
+						NameField: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the section.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceHaproxySectionsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	serverID := d.Get(ServerIdField).(int)
+
+	var sections []interface{}
+	for _, sectionType := range api.ListedSectionTypes {
+		names, err := client.ListHaproxySections(ctx, serverID, sectionType)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			sections = append(sections, map[string]interface{}{
+				TypeField: sectionType,
+				NameField: name,
+			})
+		}
+	}
+
+	if err := d.Set(SectionsField, sections); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(serverID))
+	return nil
+}
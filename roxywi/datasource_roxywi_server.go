@@ -0,0 +1,122 @@
+package roxywi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+func dataSourceServer() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServerRead,
+		Description: "Looks up a server registered in Roxy-WI by its ID, hostname or IP address, e.g. to pass its ID to section resources.",
+
+		Schema: map[string]*schema.Schema{
+			IDField: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{IDField, HostnameField, IPField},
+				Description:  "ID of the server.",
+			},
+			HostnameField: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{IDField, HostnameField, IPField},
+				Description:  "Hostname of the server.",
+			},
+			IPField: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{IDField, HostnameField, IPField},
+				Description:  "IP address of the server.",
+			},
+			CredIDField: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Credentials ID.",
+			},
+			DescriptionField: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the server.",
+			},
+			EnabledField: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Enabled state of the server.",
+			},
+			GroupIDField: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Group ID.",
+			},
+			PortField: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "SSH port of the server.",
+			},
+		},
+	}
+}
+
+func dataSourceServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+
+	var server *api.Server
+	var err error
+	if id, ok := d.GetOk(IDField); ok {
+		server, err = client.GetServer(ctx, id.(string))
+		if err != nil && server == nil {
+			return diag.FromErr(err)
+		}
+		if server.Identifier() == 0 {
+			// Not every Roxy-WI release repeats the ID in the response.
+			n, err := strconv.Atoi(id.(string))
+			if err != nil {
+				return diag.Errorf("invalid server ID '%s'", id)
+			}
+			server.ID = api.Int(n)
+		}
+	} else {
+		servers, err := client.ListServers(ctx)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		field, value := HostnameField, d.Get(HostnameField).(string)
+		if ip, ok := d.GetOk(IPField); ok {
+			field, value = IPField, ip.(string)
+		}
+
+		var matches []api.Server
+		for _, s := range servers {
+			if (field == HostnameField && string(s.Hostname) == value) || (field == IPField && string(s.IP) == value) {
+				matches = append(matches, s)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return diag.Errorf("server with %s '%s' not found", field, value)
+		case 1:
+			server = &matches[0]
+		default:
+			return diag.Errorf("%d servers have %s '%s', look the server up by %s instead", len(matches), field, value, IDField)
+		}
+	}
+
+	d.SetId(strconv.Itoa(server.Identifier()))
+	for field, value := range flattenServer(server) {
+		if field == IDField {
+			continue
+		}
+		d.Set(field, value)
+	}
+
+	return apiDiagnostics(err)
+}
@@ -0,0 +1,49 @@
+package roxywi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const IPsField = "ips"
+
+func dataSourceServerInterfaces() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServerInterfacesRead,
+		Description: "Lists the IP addresses configured on a server, as Roxy-WI sees them when it connects to the server. Use it to pick a `vip` for HA clusters and UDP listeners from what the machine actually has. Roxy-WI reports bare addresses only, so the names of the network interfaces are not available.",
+
+		Schema: map[string]*schema.Schema{
+			ServerIdField: {
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "ID of the server.",
+			},
+			IPsField: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "IP addresses configured on the server.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceServerInterfacesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	serverID := d.Get(ServerIdField).(int)
+
+	ips, err := client.GetServerIPs(ctx, serverID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set(IPsField, ips); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(serverID))
+	return nil
+}
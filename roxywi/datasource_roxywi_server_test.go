@@ -0,0 +1,98 @@
+package roxywi
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestDataSourceServers(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/server/1", map[string]interface{}{"id": 1, "hostname": "haproxy-1", "ip": "10.0.0.10", "group_id": 1, "enabled": 1, "port": 22, "cred_id": 1})
+	srv.Set("api/server/2", map[string]interface{}{"id": 2, "hostname": "haproxy-2", "ip": "10.0.0.11", "group_id": 1, "enabled": 0, "port": 22, "cred_id": 1})
+	srv.Set("api/server/3", map[string]interface{}{"id": 3, "hostname": "nginx-1", "ip": "10.0.1.10", "group_id": 2, "enabled": 1, "port": 2222, "cred_id": 2})
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_server" "by_id" {
+  id = "3"
+}
+
+data "roxywi_server" "by_hostname" {
+  hostname = "haproxy-2"
+}
+
+data "roxywi_server" "by_ip" {
+  ip = "10.0.0.10"
+}
+
+data "roxywi_servers" "all" {}
+
+data "roxywi_servers" "enabled_haproxy" {
+  enabled        = true
+  hostname_regex = "^haproxy-"
+}
+
+data "roxywi_servers" "disabled" {
+  enabled = false
+}
+
+data "roxywi_server_interfaces" "test" {
+  server_id = data.roxywi_server.by_ip.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.roxywi_server.by_id", "hostname", "nginx-1"),
+					resource.TestCheckResourceAttr("data.roxywi_server.by_id", "port", "2222"),
+					resource.TestCheckResourceAttr("data.roxywi_server.by_hostname", "id", "2"),
+					resource.TestCheckResourceAttr("data.roxywi_server.by_hostname", "enabled", "false"),
+					resource.TestCheckResourceAttr("data.roxywi_server.by_ip", "hostname", "haproxy-1"),
+
+					resource.TestCheckResourceAttr("data.roxywi_servers.all", "servers.#", "3"),
+					resource.TestCheckResourceAttr("data.roxywi_servers.all", "servers.0.id", "1"),
+					resource.TestCheckResourceAttr("data.roxywi_servers.all", "servers.2.id", "3"),
+					resource.TestCheckResourceAttr("data.roxywi_servers.enabled_haproxy", "servers.#", "1"),
+					resource.TestCheckResourceAttr("data.roxywi_servers.enabled_haproxy", "servers.0.hostname", "haproxy-1"),
+					resource.TestCheckResourceAttr("data.roxywi_servers.disabled", "servers.#", "1"),
+					resource.TestCheckResourceAttr("data.roxywi_servers.disabled", "servers.0.hostname", "haproxy-2"),
+
+					resource.TestCheckResourceAttr("data.roxywi_server_interfaces.test", "ips.#", "1"),
+					resource.TestCheckResourceAttr("data.roxywi_server_interfaces.test", "ips.0", "10.0.0.10"),
+					resource.TestCheckNoResourceAttr("data.roxywi_server_interfaces.test", "interfaces.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceServerNotFound(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/server/1", map[string]interface{}{"id": 1, "hostname": "haproxy-1", "ip": "10.0.0.10"})
+	srv.Set("api/server/2", map[string]interface{}{"id": 2, "hostname": "haproxy-1", "ip": "10.0.0.11"})
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_server" "test" {
+  hostname = "nginx-1"
+}
+`,
+				ExpectError: regexp.MustCompile(`server with hostname 'nginx-1' not found`),
+			},
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_server" "test" {
+  hostname = "haproxy-1"
+}
+`,
+				ExpectError: regexp.MustCompile(`2 servers have hostname 'haproxy-1'`),
+			},
+		},
+	})
+}
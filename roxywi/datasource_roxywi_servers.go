@@ -0,0 +1,156 @@
+package roxywi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+const HostnameRegexField = "hostname_regex"
+
+func dataSourceServers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServersRead,
+		Description: "Lists the servers registered in Roxy-WI, optionally filtered by group, enabled state or hostname.",
+
+		Schema: map[string]*schema.Schema{
+			GroupIDField: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Only return servers in this group.",
+			},
+			EnabledField: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return servers that are enabled (`true`) or disabled (`false`).",
+			},
+			HostnameRegexField: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return servers whose hostname matches this regular expression.",
+			},
+			ServersField: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching servers, ordered by ID.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						IDField: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "ID of the server.",
+						},
+						HostnameField: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Hostname of the server.",
+						},
+						IPField: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "IP address of the server.",
+						},
+						CredIDField: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Credentials ID.",
+						},
+						DescriptionField: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Description of the server.",
+						},
+						EnabledField: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Enabled state of the server.",
+						},
+						GroupIDField: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Group ID.",
+						},
+						PortField: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "SSH port of the server.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceServersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+
+	servers, err := client.ListServers(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	groupID, filterGroup := d.GetOk(GroupIDField)
+	// GetOk cannot tell enabled = false from an unset attribute.
+	raw := d.GetRawConfig()
+	filterEnabled := !raw.IsNull() && !raw.GetAttr(EnabledField).IsNull()
+	enabled := d.Get(EnabledField).(bool)
+
+	var hostnameRegex *regexp.Regexp
+	if expr, ok := d.GetOk(HostnameRegexField); ok {
+		hostnameRegex, err = regexp.Compile(expr.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Identifier() < servers[j].Identifier()
+	})
+
+	result := make([]interface{}, 0, len(servers))
+	for i := range servers {
+		server := &servers[i]
+		if filterGroup && int(server.GroupID) != groupID.(int) {
+			continue
+		}
+		if filterEnabled && bool(server.Enabled) != enabled {
+			continue
+		}
+		if hostnameRegex != nil && !hostnameRegex.MatchString(string(server.Hostname)) {
+			continue
+		}
+		result = append(result, flattenServer(server))
+	}
+
+	if err := d.Set(ServersField, result); err != nil {
+		return diag.FromErr(err)
+	}
+
+	filters := fmt.Sprintf("%v/%v/%v/%v/%s", filterGroup, groupID, filterEnabled, enabled, d.Get(HostnameRegexField))
+	d.SetId(strconv.Itoa(schema.HashString(filters)))
+	return nil
+}
+
+// flattenServer converts a server to the attributes of the server data
+// sources.
+func flattenServer(server *api.Server) map[string]interface{} {
+	return map[string]interface{}{
+		IDField:          server.Identifier(),
+		HostnameField:    string(server.Hostname),
+		IPField:          string(server.IP),
+		CredIDField:      int(server.CredID),
+		DescriptionField: string(server.Description),
+		EnabledField:     bool(server.Enabled),
+		GroupIDField:     int(server.GroupID),
+		PortField:        int(server.Port),
+	}
+}
@@ -2,12 +2,12 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -119,103 +119,71 @@ func dataSourceUdpListener() *schema.Resource {
 	}
 }
 func dataSourceUdpListenerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id, idExists := d.GetOk(ListenerIdField)
 	name, nameExists := d.GetOk(NameField)
 
-	var result map[string]interface{}
+	var listener *api.UDPListener
 	var err error
 
 	switch {
 	case idExists:
-		result, err = getListenerByID(client, id.(string))
+		listener, err = client.GetUDPListener(ctx, id.(string))
 	case nameExists:
-		result, err = getListenerByName(client, name.(string))
+		listener, err = getListenerByName(ctx, client, name.(string))
 	default:
 		return diag.Errorf("Either %s or %s must be specified", ListenerIdField, NameField)
 	}
 
-	if err != nil {
+	if err != nil && listener == nil {
 		return diag.FromErr(err)
 	}
 
-	if err := setResourceDataFromResult(d, result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	return nil
-}
-
-func getListenerByID(client *Client, id string) (map[string]interface{}, error) {
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/udp/listener/%s", id), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return append(apiDiagnostics(err), setResourceDataFromResult(d, listener)...)
 }
 
-func getListenerByName(client *Client, name string) (map[string]interface{}, error) {
-	resp, err := client.doRequest("GET", "/api/udp/listeners", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var listeners []map[string]interface{}
-	if err := json.Unmarshal(resp, &listeners); err != nil {
+// getListenerByName returns the listener named name, together with the
+// decode error of its fields if any. Some releases return the names in
+// quotes, which are ignored.
+func getListenerByName(ctx context.Context, client *api.Client, name string) (*api.UDPListener, error) {
+	listeners, err := client.ListUDPListeners(ctx)
+	if err != nil && !api.IsDecodeError(err) {
 		return nil, err
 	}
 
 	trimmedName := strings.TrimSpace(name)
-	for _, listener := range listeners {
-		listenerName := strings.TrimSpace(fmt.Sprintf("%v", listener[NameField]))
+	for i, listener := range listeners {
+		listenerName := strings.TrimSpace(string(listener.Name))
 		if strings.Trim(listenerName, "'\"") == trimmedName {
-			return listener, nil
+			return &listeners[i], api.ItemError(err, i)
 		}
 	}
 
 	return nil, fmt.Errorf("No UDP listener found with name %s", name)
 }
 
-func setResourceDataFromResult(d *schema.ResourceData, result map[string]interface{}) error {
-	id, ok := result[ListenerIdField].(float64)
-	if !ok {
-		return fmt.Errorf("Invalid ID type for listener")
+func setResourceDataFromResult(d *schema.ResourceData, listener *api.UDPListener) diag.Diagnostics {
+	if listener.ID == 0 {
+		return diag.Errorf("unable to find the listener ID in response: %+v", *listener)
 	}
-	d.SetId(fmt.Sprintf("%d", int(id)))
+	d.SetId(fmt.Sprintf("%d", int(listener.ID)))
 
-	setField(d, CheckEnabledField, result[CheckEnabledField])
-	setField(d, ClusterIdField, result[ClusterIdField])
-	setField(d, DelayBeforeRetryField, result[DelayBeforeRetryField])
-	setField(d, DelayLoopField, result[DelayLoopField])
-	setField(d, DescriptionField, result[DescriptionField])
-	setField(d, RetryField, result[RetryField])
-	setField(d, ServerIdField, result[ServerIdField])
-	setField(d, VIPField, result[VIPField])
-	setField(d, LbAlgorithmField, result[LbAlgorithmField])
-	setField(d, NameField, strings.Trim(fmt.Sprintf("%v", result[NameField]), "'\""))
-	setField(d, PortField, result[PortField])
-	setField(d, GroupIdField, result[GroupIdField])
+	d.Set(CheckEnabledField, int(listener.CheckEnabled))
+	d.Set(ClusterIdField, int(listener.ClusterID))
+	d.Set(DelayBeforeRetryField, int(listener.DelayBeforeRetry))
+	d.Set(DelayLoopField, int(listener.DelayLoop))
+	d.Set(DescriptionField, string(listener.Description))
+	d.Set(RetryField, int(listener.Retry))
+	d.Set(ServerIdField, int(listener.ServerID))
+	d.Set(VIPField, string(listener.VIP))
+	d.Set(LbAlgorithmField, string(listener.LbAlgo))
+	d.Set(NameField, strings.Trim(string(listener.Name), "'\""))
+	d.Set(PortField, int(listener.Port))
+	d.Set(GroupIdField, int(listener.GroupID))
 
-	configStr, ok := result["config"].(string)
-	if !ok || configStr == "" {
-		return d.Set(ConfigField, nil)
-	}
-
-	configStr = strings.ReplaceAll(configStr, "'", "\"")
-
-	var config []map[string]interface{}
-	if err := json.Unmarshal([]byte(configStr), &config); err != nil {
-		return fmt.Errorf("Failed to parse config field: %v", err)
-	}
-
-	if len(config) == 0 {
-		return d.Set(ConfigField, nil)
+	if len(listener.Config) == 0 {
+		d.Set(ConfigField, nil)
+		return nil
 	}
 
 	configSet := schema.NewSet(schema.HashResource(&schema.Resource{
@@ -238,48 +206,11 @@ func setResourceDataFromResult(d *schema.ResourceData, result map[string]interfa
 		},
 	}), nil)
 
-	for _, c := range config {
-		configSet.Add(map[string]interface{}{
-			BackendIPField:     getStringValue(c["backend_ip"]),
-			BackendPortField:   convertToInt(c["port"]),
-			BackendWeightField: convertToInt(c["weight"]),
-		})
+	r := newResponseDecoder(nil)
+	for _, item := range parseConfigResult(r, ConfigField, listener.Config) {
+		configSet.Add(item)
 	}
 
-	return d.Set(ConfigField, configSet)
-}
-
-func setField(d *schema.ResourceData, field string, value interface{}) {
-	if value == nil {
-		d.Set(field, "")
-		return
-	}
-	switch v := value.(type) {
-	case float64:
-		d.Set(field, int(v))
-	case int, int32, int64:
-		d.Set(field, v)
-	case string:
-		d.Set(field, v)
-	default:
-		d.Set(field, v)
-	}
-}
-
-func convertToInt(value interface{}) int {
-	switch v := value.(type) {
-	case float64:
-		return int(v)
-	case int, int32, int64:
-		return v.(int)
-	default:
-		return 0
-	}
-}
-
-func getStringValue(value interface{}) string {
-	if value == nil {
-		return ""
-	}
-	return fmt.Sprintf("%v", value)
+	r.Set(d, ConfigField, configSet)
+	return r.Diagnostics()
 }
@@ -0,0 +1,117 @@
+package roxywi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+// responseDecoder reads fields of a decoded Roxy-WI response. Values are
+// coerced from any of the representations Roxy-WI uses (numbers, numeric
+// strings, booleans, null or a missing key); a value that cannot be decoded
+// is recorded as a diagnostic on its attribute path instead of panicking.
+type responseDecoder struct {
+	result map[string]interface{}
+	path   cty.Path
+	diags  *diag.Diagnostics
+}
+
+func newResponseDecoder(result map[string]interface{}) *responseDecoder {
+	return &responseDecoder{result: result, diags: &diag.Diagnostics{}}
+}
+
+// Item returns a decoder for the index-th element of the list attribute
+// field. It shares the diagnostics of r.
+func (r *responseDecoder) Item(field string, index int, item map[string]interface{}) *responseDecoder {
+	return &responseDecoder{
+		result: item,
+		path:   r.attrPath(field).IndexInt(index),
+		diags:  r.diags,
+	}
+}
+
+func (r *responseDecoder) Int(field string) int {
+	v, err := api.ToInt(r.result[field])
+	if err != nil {
+		r.addError(field, err)
+	}
+	return v
+}
+
+func (r *responseDecoder) Bool(field string) bool {
+	v, err := api.ToBool(r.result[field])
+	if err != nil {
+		r.addError(field, err)
+	}
+	return v
+}
+
+func (r *responseDecoder) String(field string) string {
+	v, err := api.ToString(r.result[field])
+	if err != nil {
+		r.addError(field, err)
+	}
+	return v
+}
+
+// Set stores value in d and records a diagnostic if d rejects it.
+func (r *responseDecoder) Set(d *schema.ResourceData, field string, value interface{}) {
+	if err := d.Set(field, value); err != nil {
+		r.addError(field, err)
+	}
+}
+
+func (r *responseDecoder) Diagnostics() diag.Diagnostics {
+	return *r.diags
+}
+
+// apiDiagnostics returns the diagnostics of an error of the typed API. Like
+// responseDecoder, it reports the fields of an api.DecodeError on the
+// attributes of the same name; other errors are returned as they are.
+func apiDiagnostics(err error) diag.Diagnostics {
+	var decodeErr *api.DecodeError
+	if !errors.As(err, &decodeErr) {
+		return diag.FromErr(err)
+	}
+
+	r := newResponseDecoder(nil)
+	for _, field := range decodeErr.Fields {
+		r.addError(field.Field, field.Err)
+	}
+	return r.Diagnostics()
+}
+
+// apiListDiagnostics is apiDiagnostics for a list response stored in the
+// list attribute field: the fields of an api.DecodeError are reported on the
+// element at their index.
+func apiListDiagnostics(field string, err error) diag.Diagnostics {
+	var decodeErr *api.DecodeError
+	if !errors.As(err, &decodeErr) {
+		return diag.FromErr(err)
+	}
+
+	r := newResponseDecoder(nil)
+	for _, fieldErr := range decodeErr.Fields {
+		r.Item(field, fieldErr.Index, nil).addError(fieldErr.Field, fieldErr.Err)
+	}
+	return r.Diagnostics()
+}
+
+func (r *responseDecoder) attrPath(field string) cty.Path {
+	path := make(cty.Path, len(r.path), len(r.path)+1)
+	copy(path, r.path)
+	return path.GetAttr(field)
+}
+
+func (r *responseDecoder) addError(field string, err error) {
+	*r.diags = append(*r.diags, diag.Diagnostic{
+		Severity:      diag.Error,
+		Summary:       fmt.Sprintf("Unable to decode %q from the Roxy-WI response", field),
+		Detail:        err.Error(),
+		AttributePath: r.attrPath(field),
+	})
+}
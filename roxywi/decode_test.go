@@ -0,0 +1,65 @@
+package roxywi
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-roxywi/roxywi/api"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+func TestAPIDiagnostics(t *testing.T) {
+	err := &api.DecodeError{
+		Method:   "GET",
+		Endpoint: "/api/server/1",
+		Fields: []api.FieldError{
+			{Field: "port", Err: errors.New("invalid integer")},
+			{Field: "enabled", Err: errors.New("invalid boolean")},
+		},
+	}
+
+	diags := apiDiagnostics(err)
+	if len(diags) != 2 {
+		t.Fatalf("apiDiagnostics() = %d diagnostics, want one per field", len(diags))
+	}
+	for i, field := range []string{"port", "enabled"} {
+		if !diags[i].AttributePath.Equals(cty.GetAttrPath(field)) {
+			t.Errorf("diagnostic %d is on %#v, want %s", i, diags[i].AttributePath, field)
+		}
+	}
+
+	if diags := apiDiagnostics(errors.New("connection refused")); len(diags) != 1 || diags[0].AttributePath != nil {
+		t.Errorf("apiDiagnostics() of another error = %+v, want it without attribute path", diags)
+	}
+	if diags := apiDiagnostics(nil); diags != nil {
+		t.Errorf("apiDiagnostics(nil) = %+v, want none", diags)
+	}
+}
+
+// testMalformedFieldSteps returns steps that replace field of the object
+// stored at path() on srv by value, expect the refresh to fail with wantErr
+// and restore the object so that the resources can be destroyed.
+func testMalformedFieldSteps(srv *roxywitest.Server, config string, path func() string, field string, value interface{}, wantErr *regexp.Regexp) []resource.TestStep {
+	var original map[string]interface{}
+	return []resource.TestStep{
+		{
+			PreConfig: func() {
+				original, _ = srv.Get(path())
+				malformed, _ := srv.Get(path())
+				malformed[field] = value
+				srv.Set(path(), malformed)
+			},
+			Config:      config,
+			ExpectError: wantErr,
+		},
+		{
+			PreConfig: func() {
+				srv.Set(path(), original)
+			},
+			Config: config,
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package roxywi
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+const redactedValue = "***"
+
+// withLogMasking returns a context in which tflog masks the values of
+// structured fields named after sensitive keys.
+func withLogMasking(ctx context.Context) context.Context {
+	return tflog.MaskFieldValuesWithFieldKeys(ctx, api.SensitiveKeys...)
+}
+
+// redactBody returns body for logging with the values of sensitive keys
+// replaced, at any depth and inside string values holding Python literals.
+// Bodies that are not JSON are logged unchanged.
+func redactBody(body []byte) string {
+	return api.RedactJSON(body, redactedValue)
+}
+
+// withField returns a copy of fields with key set to value.
+func withField(fields map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
@@ -0,0 +1,72 @@
+package roxywi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "top-level key",
+			body: `{"login": "admin", "password": "hunter2"}`,
+			want: `{"login":"admin","password":"***"}`,
+		},
+		{
+			name: "nested key",
+			body: `{"creds": [{"name": "ssh", "private_key": "-----BEGIN"}]}`,
+			want: `{"creds":[{"name":"ssh","private_key":"***"}]}`,
+		},
+		{
+			name: "empty value is kept",
+			body: `{"password": ""}`,
+			want: `{"password":""}`,
+		},
+		{
+			name: "Python literal in a string",
+			body: `{"name": "admins", "userlist_users": "[{'user': 'admin', 'password': 'hunter2', 'group': 'ops'}]"}`,
+			want: `{"name":"admins","userlist_users":"[{\"group\":\"ops\",\"password\":\"***\",\"user\":\"admin\"}]"}`,
+		},
+		{
+			name: "JSON literal in a string",
+			body: `{"config": "{\"access_key\": \"AKIA\", \"bucket\": \"configs\"}"}`,
+			want: `{"config":"{\"access_key\":\"***\",\"bucket\":\"configs\"}"}`,
+		},
+		{
+			name: "unparsable literal is masked",
+			body: `{"userlist_users": "[{'user': 'admin', 'password': 'hunter2'"}`,
+			want: `{"userlist_users":"***"}`,
+		},
+		{
+			name: "literal without sensitive keys is kept",
+			body: `{"servers": "[{'server': '10.0.0.1', 'port': 80}]"}`,
+			want: `{"servers":"[{'server': '10.0.0.1', 'port': 80}]"}`,
+		},
+		{
+			name: "prose mentioning a key is kept",
+			body: `{"description": "rotate the password monthly"}`,
+			want: `{"description":"rotate the password monthly"}`,
+		},
+		{
+			name: "not JSON",
+			body: `password=hunter2`,
+			want: `password=hunter2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactBody([]byte(tt.body))
+			if got != tt.want {
+				t.Errorf("redactBody(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+			if strings.Contains(got, "hunter2") && strings.HasPrefix(tt.body, "{") {
+				t.Errorf("redactBody(%s) leaks the password: %s", tt.body, got)
+			}
+		})
+	}
+}
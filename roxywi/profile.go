@@ -0,0 +1,151 @@
+package roxywi
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultProfile is the profile used when none is selected.
+const DefaultProfile = "default"
+
+// profileKeys are the settings a profile may contain. They are named after
+// the provider attributes they stand in for.
+var profileKeys = map[string]bool{
+	ProviderBaseURL:        true,
+	LoginField:             true,
+	PasswordField:          true,
+	ApiTokenField:          true,
+	CredentialProcessField: true,
+	CACertFileField:        true,
+	ClientCertField:        true,
+	ClientKeyField:         true,
+	ServerNameField:        true,
+	InsecureSkipField:      true,
+}
+
+// Profile holds the settings of one section of the Roxy-WI config file. A
+// missing key is the empty string.
+type Profile map[string]string
+
+// configFilePath returns the location of the Roxy-WI config file,
+// ~/.roxywi/config unless ROXYWI_CONFIG_FILE is set.
+func configFilePath() (string, error) {
+	if path := os.Getenv("ROXYWI_CONFIG_FILE"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate the Roxy-WI config file: %w", err)
+	}
+	return filepath.Join(home, ".roxywi", "config"), nil
+}
+
+// loadProfile returns the profile name from the Roxy-WI config file. If name
+// is empty the default profile is used when it exists, and no profile at all
+// otherwise; a profile that was asked for by name must exist.
+func loadProfile(name string) (Profile, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	explicit := name != ""
+	if !explicit {
+		name = DefaultProfile
+	}
+
+	profiles, err := readConfigFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+
+	if profile[ApiTokenField] != "" && (profile[LoginField] != "" || profile[PasswordField] != "") {
+		return nil, fmt.Errorf("profile %q in %s sets both `%s` and `%s`/`%s`", name, path, ApiTokenField, LoginField, PasswordField)
+	}
+	if profile[CredentialProcessField] != "" && (profile[ApiTokenField] != "" || profile[LoginField] != "" || profile[PasswordField] != "") {
+		return nil, fmt.Errorf("profile %q in %s sets `%s` together with other credentials", name, path, CredentialProcessField)
+	}
+
+	return profile, nil
+}
+
+// readConfigFile parses an INI-style config file:
+//
+//	[default]
+//	base_url = https://roxy-wi.example.com/
+//	login    = admin
+//	password = secret
+//
+// Lines starting with '#' or ';' are comments.
+func readConfigFile(path string) (map[string]Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := make(map[string]Profile)
+	var current Profile
+	lineNo := 0
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: invalid section header %q", path, lineNo, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			// Accept the "[profile name]" spelling used by similar tools.
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			if name == "" {
+				return nil, fmt.Errorf("%s:%d: empty profile name", path, lineNo)
+			}
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = make(Profile)
+			}
+			current = profiles[name]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNo)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("%s:%d: setting outside of a profile", path, lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if !profileKeys[key] {
+			return nil, fmt.Errorf("%s:%d: unknown setting %q", path, lineNo, key)
+		}
+		current[key] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	return profiles, nil
+}
@@ -3,42 +3,178 @@ package roxywi
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 type Config struct {
 	Client *Client
+	API    *api.Client
+	// Version is the Roxy-WI release detected at configuration time.
+	Version Version
 }
 
 const (
-	ProviderBaseURL = "base_url"
-	LoginField      = "login"
-	PasswordField   = "password"
+	ProviderBaseURL        = "base_url"
+	LoginField             = "login"
+	PasswordField          = "password"
+	MaxRetriesField        = "max_retries"
+	RetryMinWaitField      = "retry_min_wait"
+	RetryMaxWaitField      = "retry_max_wait"
+	TLSField               = "tls"
+	CACertFileField        = "ca_cert_file"
+	CACertPEMField         = "ca_cert_pem"
+	ClientCertField        = "client_cert"
+	ClientKeyField         = "client_key"
+	ServerNameField        = "server_name"
+	InsecureSkipField      = "insecure_skip_verify"
+	RequestTimeoutField    = "request_timeout"
+	ReadOnlyField          = "read_only"
+	ProfileField           = "profile"
+	CredentialProcessField = "credential_process"
+	TokenCacheDirField     = "token_cache_dir"
 )
 
 func Provider() *schema.Provider {
+	return newProvider(nil)
+}
+
+// newProvider returns the provider. Tests pass wrapTransport to route the
+// client through e.g. a cassette recorder; it receives the transport built
+// from the TLS settings.
+func newProvider(wrapTransport func(http.RoundTripper) http.RoundTripper) *schema.Provider {
 	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			LoginField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Username for Roxy-WI.",
-				DefaultFunc: schema.EnvDefaultFunc("ROXYWI_USERNAME", nil),
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   fmt.Sprintf("Username for Roxy-WI. Required unless `%s` or `%s` is set.", ApiTokenField, CredentialProcessField),
+				ConflictsWith: []string{ApiTokenField, CredentialProcessField},
 			},
 			PasswordField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Password for Roxy-WI.",
-				DefaultFunc: schema.EnvDefaultFunc("ROXYWI_PASSWORD", nil),
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   fmt.Sprintf("Password for Roxy-WI. Required unless `%s` or `%s` is set.", ApiTokenField, CredentialProcessField),
+				ConflictsWith: []string{ApiTokenField, CredentialProcessField},
+			},
+			ApiTokenField: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				Description:   fmt.Sprintf("API token for Roxy-WI, used instead of `%s` and `%s`.", LoginField, PasswordField),
+				ConflictsWith: []string{LoginField, PasswordField, CredentialProcessField},
+			},
+			CredentialProcessField: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   fmt.Sprintf("Command that prints the credentials as JSON on stdout, either `{\"login\": \"...\", \"password\": \"...\"}` or `{\"token\": \"...\"}`. It is run through the system shell when the provider is configured, and again whenever Roxy-WI rejects the current token. Used instead of `%s`, `%s` and `%s`. Can also be set with the `ROXYWI_CREDENTIAL_PROCESS` environment variable.", LoginField, PasswordField, ApiTokenField),
+				ConflictsWith: []string{LoginField, PasswordField, ApiTokenField},
 			},
 			ProviderBaseURL: {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "URL to connect for Roxy-WI.",
+				Optional:    true,
+				Description: fmt.Sprintf("URL to connect for Roxy-WI, with or without a trailing slash. May include a path prefix if Roxy-WI is served under one. Required unless set by the `%s`.", ProfileField),
 				DefaultFunc: schema.EnvDefaultFunc("ROXYWI_BASE_URL", nil),
 			},
+			ProfileField: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the profile in the Roxy-WI config file (`~/.roxywi/config`, or the path in `ROXYWI_CONFIG_FILE`) to read `base_url`, credentials and TLS settings from. Settings in the provider block or in environment variables take precedence over the profile. Defaults to the `default` profile if it exists. Can also be set with the `ROXYWI_PROFILE` environment variable.",
+				DefaultFunc: schema.EnvDefaultFunc("ROXYWI_PROFILE", nil),
+			},
+			MaxRetriesField: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      DefaultMaxRetries,
+				Description:  "Maximum number of times a request is retried after a network error or a 429, 502, 503 or 504 response. `0` disables retries.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			RetryMinWaitField: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(DefaultRetryMinWait / time.Second),
+				Description:  "Minimum time in seconds to wait before the first retry. The wait doubles with each attempt.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			RetryMaxWaitField: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(DefaultRetryMaxWait / time.Second),
+				Description:  "Maximum time in seconds to wait between retries, including waits requested by a `Retry-After` header.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			RequestTimeoutField: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Timeout in seconds for each individual HTTP request to Roxy-WI. Every retry gets a fresh timeout. `0` means requests are only bounded by the timeouts of the resource operation.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			ReadOnlyField: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Refuse every API request that could change Roxy-WI, so that plans and data sources work but applies fail before anything is sent. Can also be set with the `ROXYWI_READ_ONLY` environment variable.",
+				DefaultFunc: schema.EnvDefaultFunc("ROXYWI_READ_ONLY", false),
+			},
+			TokenCacheDirField: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: fmt.Sprintf("Directory in which session tokens are cached between provider runs, keyed by `%s` and `%s` or `%s`, so that Roxy-WI is not asked to log in, nor the credential process run, for every plan. The directory is restricted to the current user, who must own it, and cached tokens that other users can access are ignored. Tokens are reused until they expire. Not used with `%s`. Can also be set with the `ROXYWI_TOKEN_CACHE_DIR` environment variable.", ProviderBaseURL, LoginField, CredentialProcessField, ApiTokenField),
+				DefaultFunc: schema.EnvDefaultFunc("ROXYWI_TOKEN_CACHE_DIR", nil),
+			},
+			TLSField: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "TLS settings for the connection to Roxy-WI. Each attribute set here, even to an empty string or `false`, takes precedence over its environment variable, which takes precedence over the profile.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						CACertFileField: {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Description:   "Path to a PEM-encoded CA bundle used to verify Roxy-WI, in addition to the system roots. Can also be set with the `ROXYWI_CA_CERT_FILE` environment variable.",
+							ConflictsWith: []string{TLSField + ".0." + CACertPEMField},
+						},
+						CACertPEMField: {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Description:   "PEM-encoded CA bundle used to verify Roxy-WI, in addition to the system roots. Can also be set with the `ROXYWI_CA_CERT_PEM` environment variable.",
+							ConflictsWith: []string{TLSField + ".0." + CACertFileField},
+						},
+						ClientCertField: {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "PEM-encoded client certificate, or a path to it, for mutual TLS. Can also be set with the `ROXYWI_CLIENT_CERT` environment variable.",
+							RequiredWith: []string{TLSField + ".0." + ClientKeyField},
+						},
+						ClientKeyField: {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							Description:  "PEM-encoded client private key, or a path to it, for mutual TLS. Can also be set with the `ROXYWI_CLIENT_KEY` environment variable.",
+							RequiredWith: []string{TLSField + ".0." + ClientCertField},
+						},
+						ServerNameField: {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Server name used to verify the Roxy-WI certificate, if it differs from the host in `base_url`. Can also be set with the `ROXYWI_TLS_SERVER_NAME` environment variable.",
+						},
+						InsecureSkipField: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Disable verification of the Roxy-WI certificate. Use only for testing. Can also be set with the `ROXYWI_INSECURE_SKIP_VERIFY` environment variable.",
+						},
+					},
+				},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"roxywi_group":                     resourceGroup(),
@@ -66,44 +202,269 @@ func Provider() *schema.Provider {
 			"roxywi_nginx_section_upstream":    resourceNginxSectionUpstream(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"roxywi_group":        dataSourceGroup(),
-			"roxywi_udp_listener": dataSourceUdpListener(),
-			"roxywi_user_role":    dataSourceUserRole(),
+			"roxywi_group":                    dataSourceGroup(),
+			"roxywi_haproxy_section_backend":  dataSourceHaproxySectionBackend(),
+			"roxywi_haproxy_section_defaults": dataSourceHaproxySectionDefaults(),
+			"roxywi_haproxy_section_frontend": dataSourceHaproxySectionFrontend(),
+			"roxywi_haproxy_section_global":   dataSourceHaproxySectionGlobal(),
+			"roxywi_haproxy_section_listen":   dataSourceHaproxySectionListen(),
+			"roxywi_haproxy_sections":         dataSourceHaproxySections(),
+			"roxywi_server":                   dataSourceServer(),
+			"roxywi_server_interfaces":        dataSourceServerInterfaces(),
+			"roxywi_servers":                  dataSourceServers(),
+			"roxywi_udp_listener":             dataSourceUdpListener(),
+			"roxywi_user_role":                dataSourceUserRole(),
 		},
 	}
 
+	traceResources(p.ResourcesMap, false)
+	traceResources(p.DataSourcesMap, true)
+
 	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		terraformVersion := p.TerraformVersion
 		if terraformVersion == "" {
 			terraformVersion = "1.0+compatible"
 		}
-		return providerConfigure(ctx, d, terraformVersion)
+
+		var diags diag.Diagnostics
+		if err := setupTracing(ctx); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Unable to set up OpenTelemetry tracing",
+				Detail:   err.Error(),
+			})
+		}
+
+		config, configureDiags := providerConfigure(ctx, d, terraformVersion, wrapTransport)
+		return config, append(diags, configureDiags...)
 	}
 
 	return p
 }
 
 func providerConfigure(
-	_ context.Context,
+	ctx context.Context,
 	d *schema.ResourceData,
 	terraformVersion string,
+	wrapTransport func(http.RoundTripper) http.RoundTripper,
 ) (interface{}, diag.Diagnostics) {
-	username := d.Get(LoginField).(string)
-	password := d.Get(PasswordField).(string)
 	apiEndpoint := d.Get(ProviderBaseURL).(string)
 
+	profile, err := loadProfile(d.Get(ProfileField).(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	if apiEndpoint == "" {
+		apiEndpoint = profile[ProviderBaseURL]
+	}
+	creds := resolveCredentials(d, profile)
+
+	if apiEndpoint == "" {
+		return nil, diag.Errorf("`%s` must be set in the provider configuration, the ROXYWI_BASE_URL environment variable or the profile", ProviderBaseURL)
+	}
+	if creds.CredentialProcess != "" && (creds.APIToken != "" || creds.Login != "" || creds.Password != "") {
+		return nil, diag.Errorf("`%s` cannot be used together with `%s`, `%s` or `%s`, check the ROXYWI_CREDENTIAL_PROCESS, ROXYWI_API_TOKEN, ROXYWI_USERNAME and ROXYWI_PASSWORD environment variables", CredentialProcessField, ApiTokenField, LoginField, PasswordField)
+	}
+	if creds.APIToken != "" && (creds.Login != "" || creds.Password != "") {
+		return nil, diag.Errorf("`%s` cannot be used together with `%s` and `%s`, check the ROXYWI_API_TOKEN, ROXYWI_USERNAME and ROXYWI_PASSWORD environment variables", ApiTokenField, LoginField, PasswordField)
+	}
+	if creds.CredentialProcess == "" && creds.APIToken == "" && (creds.Login == "" || creds.Password == "") {
+		return nil, diag.Errorf("either `%s`, `%s` or both `%s` and `%s` must be set", ApiTokenField, CredentialProcessField, LoginField, PasswordField)
+	}
+
 	userAgent := fmt.Sprintf("terraform/%s", terraformVersion)
 
 	var diags diag.Diagnostics
 
-	client, err := NewClient(apiEndpoint, username, password, userAgent)
+	retry := RetryPolicy{
+		MaxRetries: d.Get(MaxRetriesField).(int),
+		MinWait:    time.Duration(d.Get(RetryMinWaitField).(int)) * time.Second,
+		MaxWait:    time.Duration(d.Get(RetryMaxWaitField).(int)) * time.Second,
+	}
+	if retry.MinWait > retry.MaxWait {
+		return nil, diag.Errorf("`%s` (%d) must not be greater than `%s` (%d)", RetryMinWaitField, d.Get(RetryMinWaitField).(int), RetryMaxWaitField, d.Get(RetryMaxWaitField).(int))
+	}
+
+	tlsSettings, err := providerTLSSettings(d, profile)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
+	if tlsSettings.InsecureSkipVerify {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "TLS certificate verification is disabled",
+			Detail:   fmt.Sprintf("`%s` is enabled, so the identity of Roxy-WI at %s is not verified.", InsecureSkipField, apiEndpoint),
+		})
+	}
+
+	opts := []ClientOption{
+		WithRetryPolicy(retry),
+		WithTLSSettings(tlsSettings),
+		WithRequestTimeout(time.Duration(d.Get(RequestTimeoutField).(int)) * time.Second),
+		WithReadOnly(d.Get(ReadOnlyField).(bool)),
+	}
+	if creds.APIToken != "" {
+		opts = append(opts, WithAPIToken(creds.APIToken))
+	}
+	if creds.CredentialProcess != "" {
+		opts = append(opts, WithCredentialProcess(creds.CredentialProcess))
+	}
+	if dir := d.Get(TokenCacheDirField).(string); dir != "" {
+		opts = append(opts, WithTokenCache(dir))
+	}
 
+	if wrapTransport != nil {
+		transport, err := tlsSettings.Transport()
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		opts = append(opts, WithTransport(wrapTransport(transport)))
+	}
+
+	client, err := NewClient(ctx, apiEndpoint, creds.Login, creds.Password, userAgent, opts...)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	apiClient := api.New(client)
 	config := &Config{
-		Client: client,
+		Client:  client,
+		API:     apiClient,
+		Version: detectVersion(ctx, apiClient),
 	}
 
 	return config, diags
 }
+
+// Sources of a provider setting, in order of precedence.
+const (
+	settingFromConfig = iota
+	settingFromEnv
+	settingFromProfile
+)
+
+// Credentials are the settings Roxy-WI is authenticated with.
+type Credentials struct {
+	Login             string
+	Password          string
+	APIToken          string
+	CredentialProcess string
+}
+
+// resolveCredentials resolves the credentials as a group from the provider
+// block, then the ROXYWI_* environment variables, then the profile: the
+// first source that sets any of them provides all of them, so that an
+// api_token in the provider block is never combined with a ROXYWI_USERNAME
+// left in the environment.
+func resolveCredentials(d *schema.ResourceData, profile Profile) Credentials {
+	config := d.GetRawConfig()
+	attr := func(name string) string {
+		if config.IsNull() || !config.IsKnown() {
+			return ""
+		}
+		if v := config.GetAttr(name); !v.IsNull() && v.IsKnown() {
+			return v.AsString()
+		}
+		return ""
+	}
+
+	sources := []Credentials{
+		{
+			Login:             attr(LoginField),
+			Password:          attr(PasswordField),
+			APIToken:          attr(ApiTokenField),
+			CredentialProcess: attr(CredentialProcessField),
+		},
+		{
+			Login:             os.Getenv("ROXYWI_USERNAME"),
+			Password:          os.Getenv("ROXYWI_PASSWORD"),
+			APIToken:          os.Getenv("ROXYWI_API_TOKEN"),
+			CredentialProcess: os.Getenv("ROXYWI_CREDENTIAL_PROCESS"),
+		},
+	}
+	for _, creds := range sources {
+		if creds != (Credentials{}) {
+			return creds
+		}
+	}
+	return Credentials{
+		Login:             profile[LoginField],
+		Password:          profile[PasswordField],
+		APIToken:          profile[ApiTokenField],
+		CredentialProcess: profile[CredentialProcessField],
+	}
+}
+
+// providerTLSSettings resolves each TLS setting on its own from the tls
+// block, then its ROXYWI_* environment variable, then the profile. An
+// attribute set in the tls block wins even when it is empty or false.
+func providerTLSSettings(d *schema.ResourceData, profile Profile) (TLSSettings, error) {
+	block := configBlock(d.GetRawConfig(), TLSField)
+
+	setting := func(field, env string) (string, int) {
+		if !block.IsNull() {
+			if v := block.GetAttr(field); !v.IsNull() && v.IsKnown() {
+				if v.Type() == cty.Bool {
+					return strconv.FormatBool(v.True()), settingFromConfig
+				}
+				return v.AsString(), settingFromConfig
+			}
+		}
+		if v := os.Getenv(env); v != "" {
+			return v, settingFromEnv
+		}
+		return profile[field], settingFromProfile
+	}
+
+	var settings TLSSettings
+	settings.ClientCert, _ = setting(ClientCertField, "ROXYWI_CLIENT_CERT")
+	settings.ClientKey, _ = setting(ClientKeyField, "ROXYWI_CLIENT_KEY")
+	settings.ServerName, _ = setting(ServerNameField, "ROXYWI_TLS_SERVER_NAME")
+
+	if v, source := setting(InsecureSkipField, "ROXYWI_INSECURE_SKIP_VERIFY"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil && source == settingFromEnv {
+			return settings, fmt.Errorf("invalid value for ROXYWI_INSECURE_SKIP_VERIFY: %w", err)
+		}
+		if err != nil {
+			return settings, fmt.Errorf("invalid value for `%s` in the profile: %w", InsecureSkipField, err)
+		}
+		settings.InsecureSkipVerify = insecure
+	}
+
+	// The CA bundle is one setting given either as a file or as PEM: the
+	// source with the highest precedence that sets either of them wins.
+	caFile, caFileSource := setting(CACertFileField, "ROXYWI_CA_CERT_FILE")
+	caPEM, caPEMSource := setting(CACertPEMField, "ROXYWI_CA_CERT_PEM")
+	// Profiles cannot hold PEM, which spans several lines, and the tls block
+	// accepts only one of the two, so both can only come from the environment.
+	if caFile != "" && caPEM != "" {
+		switch {
+		case caFileSource < caPEMSource:
+			caPEM = ""
+		case caPEMSource < caFileSource:
+			caFile = ""
+		default:
+			return settings, fmt.Errorf("only one of ROXYWI_CA_CERT_FILE and ROXYWI_CA_CERT_PEM can be set")
+		}
+	}
+	settings.CACertFile = caFile
+	settings.CACertPEM = caPEM
+
+	return settings, nil
+}
+
+// configBlock returns the single nested block name of the raw configuration
+// value, or a null object when the block is not set.
+func configBlock(config cty.Value, name string) cty.Value {
+	blockType := config.Type().AttributeType(name).ElementType()
+	if config.IsNull() || !config.IsKnown() {
+		return cty.NullVal(blockType)
+	}
+	blocks := config.GetAttr(name)
+	if blocks.IsNull() || !blocks.IsKnown() || blocks.LengthInt() == 0 {
+		return cty.NullVal(blockType)
+	}
+	return blocks.Index(cty.NumberIntVal(0))
+}
@@ -0,0 +1,215 @@
+package roxywi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/cassette"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+// cassetteDir holds the committed cassettes, in one directory per supported
+// Roxy-WI major version, e.g. testdata/cassettes/8.x/group.json.
+const cassetteDir = "testdata/cassettes"
+
+// Setting ROXYWI_RECORD_BASE_URL and ROXYWI_RECORD_API_TOKEN makes
+// TestCassettes record the cassettes again against that Roxy-WI instead of
+// replaying them. They are written to the directory of the major version the
+// server reports, or of ROXYWI_RECORD_VERSION for releases without
+// /api/version. Recording creates and deletes a group named "web".
+const (
+	recordBaseURLEnv  = "ROXYWI_RECORD_BASE_URL"
+	recordAPITokenEnv = "ROXYWI_RECORD_API_TOKEN"
+	recordVersionEnv  = "ROXYWI_RECORD_VERSION"
+)
+
+// cassetteProviderFactories serves providers whose client sends every request
+// through rt.
+func cassetteProviderFactories(rt http.RoundTripper) map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"roxywi": func() (*schema.Provider, error) {
+			return newProvider(func(http.RoundTripper) http.RoundTripper { return rt }), nil
+		},
+	}
+}
+
+// testCassetteGroupStep creates the group of testGroupConfig. credentials are
+// the provider arguments to authenticate with.
+func testCassetteGroupStep(baseURL, credentials string) resource.TestStep {
+	config := fmt.Sprintf(`
+provider "roxywi" {
+  base_url       = %q
+  retry_min_wait = 0
+  retry_max_wait = 1
+%s
+}
+`, baseURL, credentials) + testGroupConfig
+
+	return resource.TestStep{
+		Config: config,
+		Check:  resource.TestCheckResourceAttr("roxywi_group.test", "name", "web"),
+	}
+}
+
+func testCassetteLogin() string {
+	return fmt.Sprintf("  login    = %q\n  password = %q", roxywitest.DefaultLogin, roxywitest.DefaultPassword)
+}
+
+func testCassetteAPIToken(token string) string {
+	return fmt.Sprintf("  api_token = %q", token)
+}
+
+// TestCassetteRecordAndReplay records a group being created and destroyed
+// against the fake server and replays it under a different base_url without
+// any server behind it.
+func TestCassetteRecordAndReplay(t *testing.T) {
+	srv := newTestServer(t)
+	name := filepath.Join(t.TempDir(), "group.json")
+
+	recorder, err := cassette.NewRecorder(srv.URL+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unitTest(t, resource.TestCase{
+		ProviderFactories: cassetteProviderFactories(recorder),
+		Steps:             []resource.TestStep{testCassetteGroupStep(srv.URL+"/", testCassetteLogin())},
+	})
+	if err := recorder.Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	const replayURL = "https://roxy-wi.invalid/roxy-wi/"
+	replayer, err := cassette.NewReplayer(name, replayURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unitTest(t, resource.TestCase{
+		ProviderFactories: cassetteProviderFactories(replayer),
+		Steps:             []resource.TestStep{testCassetteGroupStep(replayURL, testCassetteLogin())},
+	})
+
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("the replay skipped %d recorded requests, e.g. %+v", len(unused), unused[0].Request)
+	}
+}
+
+// cassetteVersion returns the version Roxy-WI reported on GET /api/version
+// in c, which is unknown if the request failed or was not recorded.
+func cassetteVersion(c *cassette.Cassette) Version {
+	for _, interaction := range c.Interactions {
+		if interaction.Request.Method != http.MethodGet || interaction.Request.Path != "api/version" || interaction.Response.StatusCode != http.StatusOK {
+			continue
+		}
+		var raw struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal([]byte(interaction.Response.Body), &raw); err != nil {
+			return Version{}
+		}
+		version, _ := parseVersion(raw.Version)
+		return version
+	}
+	return Version{}
+}
+
+// TestCassettes replays the committed cassettes of every supported Roxy-WI
+// major version without any server behind them, or records them again, see
+// recordBaseURLEnv.
+func TestCassettes(t *testing.T) {
+	if baseURL := os.Getenv(recordBaseURLEnv); baseURL != "" {
+		recordCassettes(t, baseURL, os.Getenv(recordAPITokenEnv))
+		return
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(cassetteDir, "*.x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatalf("no cassettes in %s", cassetteDir)
+	}
+
+	for _, dir := range dirs {
+		dir := dir
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			isolateProviderEnv(t)
+			name := filepath.Join(dir, "group.json")
+
+			c, err := cassette.Load(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version := cassetteVersion(c); version.IsKnown() && fmt.Sprintf("%d.x", version.Major) != filepath.Base(dir) {
+				t.Fatalf("%s was recorded against Roxy-WI %s", name, version)
+			}
+
+			const replayURL = "https://roxy-wi.invalid/roxy-wi/"
+			replayer, err := cassette.NewReplayer(name, replayURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			unitTest(t, resource.TestCase{
+				ProviderFactories: cassetteProviderFactories(replayer),
+				Steps:             []resource.TestStep{testCassetteGroupStep(replayURL, testCassetteAPIToken(cassette.Redacted))},
+			})
+
+			if unused := replayer.Unused(); len(unused) != 0 {
+				t.Errorf("the replay skipped %d recorded requests, e.g. %+v", len(unused), unused[0].Request)
+			}
+		})
+	}
+}
+
+// recordCassettes records the cassettes of TestCassettes against the Roxy-WI
+// at baseURL.
+func recordCassettes(t *testing.T, baseURL, token string) {
+	if token == "" {
+		t.Fatalf("%s must be set together with %s", recordAPITokenEnv, recordBaseURLEnv)
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	isolateProviderEnv(t)
+
+	recorder, err := cassette.NewRecorder(baseURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unitTest(t, resource.TestCase{
+		ProviderFactories: cassetteProviderFactories(recorder),
+		Steps:             []resource.TestStep{testCassetteGroupStep(baseURL, testCassetteAPIToken(token))},
+	})
+
+	recorded := filepath.Join(t.TempDir(), "group.json")
+	if err := recorder.Save(recorded); err != nil {
+		t.Fatal(err)
+	}
+	c, err := cassette.Load(recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := os.Getenv(recordVersionEnv)
+	if version := cassetteVersion(c); version.IsKnown() {
+		dir = fmt.Sprintf("%d.x", version.Major)
+	}
+	if dir == "" {
+		t.Fatalf("the server does not report its version, set %s to its major version, e.g. 7.x", recordVersionEnv)
+	}
+
+	dir = filepath.Join(cassetteDir, dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(filepath.Join(dir, "group.json")); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("recorded %s", filepath.Join(dir, "group.json"))
+}
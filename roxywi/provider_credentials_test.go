@@ -0,0 +1,135 @@
+package roxywi
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+const testCredentialsToken = "test-token"
+
+// testCredentialsConfig returns a configuration creating a group on srv with
+// the given credential arguments in the provider block.
+func testCredentialsConfig(srv *roxywitest.Server, credentials string) string {
+	return fmt.Sprintf(`
+provider "roxywi" {
+  base_url       = %q
+  retry_min_wait = 0
+  retry_max_wait = 1
+%s
+}
+`, srv.URL+"/", credentials) + testGroupConfig
+}
+
+func TestProviderCredentialPrecedence(t *testing.T) {
+	loginConfig := fmt.Sprintf("  login    = %q\n  password = %q", roxywitest.DefaultLogin, roxywitest.DefaultPassword)
+	tokenConfig := fmt.Sprintf("  api_token = %q", testCredentialsToken)
+	loginEnv := map[string]string{
+		"ROXYWI_USERNAME": roxywitest.DefaultLogin,
+		"ROXYWI_PASSWORD": roxywitest.DefaultPassword,
+	}
+	loginProfile := fmt.Sprintf("login = %s\npassword = %s", roxywitest.DefaultLogin, roxywitest.DefaultPassword)
+
+	tests := []struct {
+		name       string
+		config     string
+		env        map[string]string
+		profile    string
+		shell      bool
+		wantLogins bool
+		wantErr    *regexp.Regexp
+	}{
+		{
+			name:   "configuration token ignores environment login",
+			config: tokenConfig,
+			env:    map[string]string{"ROXYWI_USERNAME": "someone", "ROXYWI_PASSWORD": "wrong"},
+		},
+		{
+			name:       "configuration login ignores environment token",
+			config:     loginConfig,
+			env:        map[string]string{"ROXYWI_API_TOKEN": "wrong"},
+			wantLogins: true,
+		},
+		{
+			name:   "configuration credential process ignores environment login",
+			config: fmt.Sprintf("  credential_process = %q", `echo '{"token": "`+testCredentialsToken+`"}'`),
+			env:    map[string]string{"ROXYWI_USERNAME": "someone"},
+			shell:  true,
+		},
+		{
+			name:    "environment token ignores profile login",
+			env:     map[string]string{"ROXYWI_API_TOKEN": testCredentialsToken},
+			profile: "login = someone\npassword = wrong",
+		},
+		{
+			name:       "environment login ignores profile token",
+			env:        loginEnv,
+			profile:    "api_token = wrong",
+			wantLogins: true,
+		},
+		{
+			name:       "profile login",
+			profile:    loginProfile,
+			wantLogins: true,
+		},
+		{
+			name: "token and login in the environment",
+			env: map[string]string{
+				"ROXYWI_API_TOKEN": testCredentialsToken,
+				"ROXYWI_USERNAME":  roxywitest.DefaultLogin,
+			},
+			wantErr: regexp.MustCompile("`api_token` cannot be used together with `login` and `password`"),
+		},
+		{
+			name:    "no credentials",
+			wantErr: regexp.MustCompile("either `api_token`, `credential_process` or both `login` and `password`\\s+must be set"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.shell {
+				skipWithoutShell(t)
+			}
+			srv := newTestServer(t, roxywitest.WithAPIToken(testCredentialsToken))
+
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if tt.profile != "" {
+				profile := "[default]\n" + tt.profile + "\n"
+				if err := os.WriteFile(os.Getenv("ROXYWI_CONFIG_FILE"), []byte(profile), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			step := resource.TestStep{
+				Config:      testCredentialsConfig(srv, tt.config),
+				ExpectError: tt.wantErr,
+			}
+			if tt.wantErr == nil {
+				step.Check = testCheckExists(srv, "roxywi_group.test", idPath("api/group"))
+			}
+			unitTest(t, resource.TestCase{
+				ProviderFactories: testProviderFactories,
+				Steps:             []resource.TestStep{step},
+			})
+
+			if tt.wantErr != nil {
+				return
+			}
+			logins := len(requestsTo(srv, http.MethodPost, `^api/login$`))
+			if tt.wantLogins && logins == 0 {
+				t.Error("the provider did not log in, want it to use the login and password")
+			}
+			if !tt.wantLogins && logins != 0 {
+				t.Errorf("the provider logged in %d times, want it to use the API token", logins)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package roxywi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	install "github.com/hashicorp/hc-install"
+	"github.com/hashicorp/hc-install/checkpoint"
+	"github.com/hashicorp/hc-install/fs"
+	"github.com/hashicorp/hc-install/product"
+	"github.com/hashicorp/hc-install/src"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+// providerEnvVars are the environment variables read by the provider. They
+// are cleared for every test so that the settings of the developer running
+// the tests do not leak into them.
+var providerEnvVars = []string{
+	"ROXYWI_API_TOKEN",
+	"ROXYWI_BASE_URL",
+	"ROXYWI_CA_CERT_FILE",
+	"ROXYWI_CA_CERT_PEM",
+	"ROXYWI_CLIENT_CERT",
+	"ROXYWI_CLIENT_KEY",
+	"ROXYWI_CREDENTIAL_PROCESS",
+	"ROXYWI_INSECURE_SKIP_VERIFY",
+	"ROXYWI_PASSWORD",
+	"ROXYWI_PROFILE",
+	"ROXYWI_READ_ONLY",
+	"ROXYWI_TLS_SERVER_NAME",
+	"ROXYWI_TOKEN_CACHE_DIR",
+	"ROXYWI_USERNAME",
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+}
+
+// terraformUnavailable is why the Terraform CLI could not be found or
+// installed, in which case the tests running Terraform are skipped.
+var terraformUnavailable error
+
+// TestMain finds the Terraform CLI once for all tests, in the same places as
+// the SDK: TF_ACC_TERRAFORM_PATH, then PATH, then the latest release, which
+// is downloaded to a directory removed afterwards. Without network access
+// and without a binary, the tests running Terraform are skipped instead of
+// failing one by one.
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") != "" || os.Getenv("TF_ACC_TERRAFORM_VERSION") != "" {
+		return m.Run()
+	}
+
+	dir, err := os.MkdirTemp("", "roxywi-terraform")
+	if err != nil {
+		terraformUnavailable = err
+		return m.Run()
+	}
+	defer os.RemoveAll(dir)
+
+	path, err := install.NewInstaller().Ensure(context.Background(), []src.Source{
+		&fs.AnyVersion{Product: &product.Terraform},
+		&checkpoint.LatestVersion{Product: product.Terraform, InstallDir: dir},
+	})
+	if err != nil {
+		terraformUnavailable = err
+		return m.Run()
+	}
+
+	os.Setenv("TF_ACC_TERRAFORM_PATH", path)
+	return m.Run()
+}
+
+// unitTest runs testCase with resource.UnitTest, or skips t if there is no
+// Terraform CLI to run it with.
+func unitTest(t *testing.T, testCase resource.TestCase) {
+	t.Helper()
+
+	if terraformUnavailable != nil {
+		t.Skipf("Terraform CLI not available, set TF_ACC_TERRAFORM_PATH to run this test: %v", terraformUnavailable)
+	}
+	resource.UnitTest(t, testCase)
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testProviderFactories serves a fresh provider to every Terraform command
+// run by resource.UnitTest.
+var testProviderFactories = map[string]func() (*schema.Provider, error){
+	"roxywi": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
+}
+
+// isolateProviderEnv isolates the provider from the environment and config
+// file of the developer running the tests.
+func isolateProviderEnv(t *testing.T) {
+	t.Helper()
+
+	for _, name := range providerEnvVars {
+		t.Setenv(name, "")
+	}
+	t.Setenv("ROXYWI_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+}
+
+// newTestServer starts a fake Roxy-WI server that is closed at the end of the
+// test, and isolates the provider from the environment and config file of
+// the developer running the tests.
+func newTestServer(t *testing.T, opts ...roxywitest.Option) *roxywitest.Server {
+	t.Helper()
+
+	isolateProviderEnv(t)
+	srv := roxywitest.NewServer(opts...)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testProviderConfig returns a provider block for srv that retries quickly.
+func testProviderConfig(srv *roxywitest.Server) string {
+	return fmt.Sprintf(`
+provider "roxywi" {
+  base_url       = %q
+  login          = %q
+  password       = %q
+  retry_min_wait = 0
+  retry_max_wait = 1
+}
+`, srv.URL+"/", roxywitest.DefaultLogin, roxywitest.DefaultPassword)
+}
+
+// testCheckDestroyed verifies that the objects of resourceType are gone from
+// srv after destroy. path returns the API path of the object of a state
+// entry.
+func testCheckDestroyed(srv *roxywitest.Server, resourceType string, path func(*terraform.ResourceState) string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			p := path(rs)
+			if _, ok := srv.Get(p); ok {
+				return fmt.Errorf("%s %s still exists at %s", resourceType, rs.Primary.ID, p)
+			}
+		}
+		return nil
+	}
+}
+
+// testCheckExists verifies that the object of the resource at address exists
+// on srv.
+func testCheckExists(srv *roxywitest.Server, address string, path func(*terraform.ResourceState) string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("%s not found in state", address)
+		}
+		p := path(rs)
+		if _, ok := srv.Get(p); !ok {
+			return fmt.Errorf("%s %s not found at %s", address, rs.Primary.ID, p)
+		}
+		return nil
+	}
+}
+
+// idPath returns a path function for objects stored under collection by ID.
+func idPath(collection string) func(*terraform.ResourceState) string {
+	return func(rs *terraform.ResourceState) string {
+		return collection + "/" + rs.Primary.ID
+	}
+}
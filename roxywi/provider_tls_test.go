@@ -0,0 +1,148 @@
+package roxywi
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+// testTLSConfig returns a configuration creating a group on srv with the
+// given tls block.
+func testTLSConfig(srv *roxywitest.Server, tlsBlock string) string {
+	return fmt.Sprintf(`
+provider "roxywi" {
+  base_url       = %q
+  login          = %q
+  password       = %q
+  retry_min_wait = 0
+  retry_max_wait = 1
+%s
+}
+`, srv.URL+"/", roxywitest.DefaultLogin, roxywitest.DefaultPassword, tlsBlock) + testGroupConfig
+}
+
+// writeCACert writes the certificate of srv to a PEM file and returns its
+// path.
+func writeCACert(t *testing.T, srv *roxywitest.Server) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+var errUnknownAuthority = regexp.MustCompile(`certificate signed by unknown authority`)
+
+func TestProviderTLSPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		profile  string
+		tlsBlock func(caFile string) string
+		wantErr  *regexp.Regexp
+	}{
+		{
+			name:     "environment skips verification",
+			env:      map[string]string{"ROXYWI_INSECURE_SKIP_VERIFY": "true"},
+			tlsBlock: func(string) string { return "" },
+		},
+		{
+			name: "configuration false overrides environment true",
+			env:  map[string]string{"ROXYWI_INSECURE_SKIP_VERIFY": "true"},
+			tlsBlock: func(string) string {
+				return `tls { insecure_skip_verify = false }`
+			},
+			wantErr: errUnknownAuthority,
+		},
+		{
+			name:     "profile skips verification",
+			profile:  "insecure_skip_verify = true",
+			tlsBlock: func(string) string { return "" },
+		},
+		{
+			name:    "configuration false overrides profile true",
+			profile: "insecure_skip_verify = true",
+			tlsBlock: func(string) string {
+				return `tls { insecure_skip_verify = false }`
+			},
+			wantErr: errUnknownAuthority,
+		},
+		{
+			name:     "environment false overrides profile true",
+			env:      map[string]string{"ROXYWI_INSECURE_SKIP_VERIFY": "false"},
+			profile:  "insecure_skip_verify = true",
+			tlsBlock: func(string) string { return "" },
+			wantErr:  errUnknownAuthority,
+		},
+		{
+			name: "configuration CA file overrides environment CA PEM",
+			env:  map[string]string{"ROXYWI_CA_CERT_PEM": "not a certificate"},
+			tlsBlock: func(caFile string) string {
+				return fmt.Sprintf(`tls { ca_cert_file = %q }`, caFile)
+			},
+		},
+		{
+			name:     "environment CA PEM overrides profile CA file",
+			env:      map[string]string{"ROXYWI_CA_CERT_PEM": "not a certificate"},
+			profile:  "ca_cert_file = CA_FILE",
+			tlsBlock: func(string) string { return "" },
+			wantErr:  regexp.MustCompile(`no valid PEM certificates found in the CA bundle`),
+		},
+		{
+			name:     "CA PEM in the profile",
+			profile:  "ca_cert_pem = not a certificate",
+			tlsBlock: func(string) string { return "" },
+			wantErr:  regexp.MustCompile(`unknown setting "ca_cert_pem"`),
+		},
+		{
+			name: "both CA settings in the environment",
+			env: map[string]string{
+				"ROXYWI_CA_CERT_FILE": "CA_FILE",
+				"ROXYWI_CA_CERT_PEM":  "not a certificate",
+			},
+			tlsBlock: func(string) string { return "" },
+			wantErr:  regexp.MustCompile(`only one of ROXYWI_CA_CERT_FILE and ROXYWI_CA_CERT_PEM can be set`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, roxywitest.WithTLS())
+			caFile := writeCACert(t, srv)
+
+			for name, value := range tt.env {
+				if value == "CA_FILE" {
+					value = caFile
+				}
+				t.Setenv(name, value)
+			}
+			if tt.profile != "" {
+				profile := "[default]\n" + strings.ReplaceAll(tt.profile, "CA_FILE", caFile) + "\n"
+				if err := os.WriteFile(os.Getenv("ROXYWI_CONFIG_FILE"), []byte(profile), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			step := resource.TestStep{
+				Config:      testTLSConfig(srv, tt.tlsBlock(caFile)),
+				ExpectError: tt.wantErr,
+			}
+			if tt.wantErr == nil {
+				step.Check = testCheckExists(srv, "roxywi_group.test", idPath("api/group"))
+			}
+			unitTest(t, resource.TestCase{
+				ProviderFactories: testProviderFactories,
+				Steps:             []resource.TestStep{step},
+			})
+		})
+	}
+}
@@ -2,15 +2,13 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"log"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -25,13 +23,13 @@ const (
 
 func resourceChannel() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceChannelCreate,
-		ReadWithoutTimeout:   resourceChannelRead,
-		UpdateWithoutTimeout: resourceChannelUpdate,
-		DeleteWithoutTimeout: resourceChannelDelete,
+		CreateContext: resourceChannelCreate,
+		ReadContext:   resourceChannelRead,
+		UpdateContext: resourceChannelUpdate,
+		DeleteContext: resourceChannelDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceChannelImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -70,87 +68,75 @@ func resourceChannel() *schema.Resource {
 }
 
 func resourceChannelCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	receiver := d.Get(ReceiverField).(string)
-
-	channel := map[string]interface{}{
-		ReceiverField: receiver,
-		ChannelField:  strings.ReplaceAll(d.Get(ChannelField).(string), "'", ""),
-		GroupIDField:  d.Get(GroupIDField).(int),
-		TokenField:    d.Get(TokenField).(string),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("/api/channel/%s", receiver), channel)
+	id, err := client.CreateChannel(ctx, expandChannel(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Printf("API response: %s", resp)
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d", int(id)))
+	d.SetId(id)
 	return resourceChannelRead(ctx, d, m)
 }
 
 func resourceChannelRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 	receiver := d.Get(ReceiverField).(string)
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/channel/%s/%s", receiver, id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	channel, err := client.GetChannel(ctx, receiver, id)
+	if err != nil && channel == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	if receiverValue, ok := result[ReceiverField].(string); ok && receiverValue != "" {
-		d.Set(ReceiverField, receiverValue)
+	// Roxy-WI may leave out or blank the token, so only values it reports
+	// replace the ones in state.
+	for field, value := range map[string]api.String{
+		ReceiverField: channel.Receiver,
+		ChannelField:  channel.Channel,
+		TokenField:    channel.Token,
+	} {
+		if value != "" {
+			d.Set(field, string(value))
+		}
 	}
-
-	if channelValue, ok := result[ChannelField].(string); ok && channelValue != "" {
-		channel := strings.ReplaceAll(channelValue, "'", "")
-		d.Set(ChannelField, channel)
+	if channel.GroupID != nil {
+		d.Set(GroupIDField, int(*channel.GroupID))
 	}
 
-	if groupIDValue, ok := result[GroupIDField].(float64); ok {
-		d.Set(GroupIDField, int(groupIDValue))
-	}
+	return apiDiagnostics(err)
+}
 
-	if tokenValue, ok := result[TokenField].(string); ok && tokenValue != "" {
-		d.Set(TokenField, tokenValue)
+// resourceChannelImport finds the receiver of the imported channel, which is
+// part of its API path but not of its ID, by trying each receiver type.
+func resourceChannelImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	client := m.(*Config).API
+
+	for _, receiver := range []string{ReceiverTypeTelegram, ReceiverTypeSlack, ReceiverTypePagerDuty, ReceiverTypeMattermost} {
+		_, err := client.GetChannel(ctx, receiver, d.Id())
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil && !api.IsDecodeError(err) {
+			return nil, err
+		}
+
+		d.Set(ReceiverField, receiver)
+		return []*schema.ResourceData{d}, nil
 	}
 
-	return nil
+	return nil, fmt.Errorf("channel %s not found", d.Id())
 }
 
 func resourceChannelUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
-	receiver := d.Get(ReceiverField).(string)
 
-	channel := map[string]interface{}{
-		ReceiverField: d.Get(ReceiverField).(string),
-		ChannelField:  strings.ReplaceAll(d.Get(ChannelField).(string), "'", ""),
-		GroupIDField:  d.Get(GroupIDField).(int),
-		TokenField:    d.Get(TokenField).(string),
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/channel/%s/%s", receiver, id), channel)
-	if err != nil {
+	if err := client.UpdateChannel(ctx, id, expandChannel(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -158,15 +144,24 @@ func resourceChannelUpdate(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceChannelDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 	receiver := d.Get(ReceiverField).(string)
 
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/channel/%s/%s", receiver, id), nil)
-	if err != nil {
+	if err := client.DeleteChannel(ctx, receiver, id); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandChannel(d *schema.ResourceData) *api.Channel {
+	groupID := api.Int(d.Get(GroupIDField).(int))
+	return &api.Channel{
+		Receiver: api.String(d.Get(ReceiverField).(string)),
+		Channel:  api.String(d.Get(ChannelField).(string)),
+		GroupID:  &groupID,
+		Token:    api.String(d.Get(TokenField).(string)),
+	}
+}
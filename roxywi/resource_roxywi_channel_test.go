@@ -0,0 +1,94 @@
+package roxywi
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceChannel(t *testing.T) {
+	srv := newTestServer(t)
+	channelPath := func(rs *terraform.ResourceState) string {
+		return "api/channel/" + rs.Primary.Attributes["receiver"] + "/" + rs.Primary.ID
+	}
+
+	config := func(channel string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_channel" "test" {
+  receiver = "slack"
+  channel  = "` + channel + `"
+  group_id = 1
+  token    = "xoxb-token"
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_channel", channelPath),
+		Steps: []resource.TestStep{
+			{
+				Config: config("alerts"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_channel.test", channelPath),
+					resource.TestCheckResourceAttr("roxywi_channel.test", "channel", "alerts"),
+					resource.TestCheckResourceAttr("roxywi_channel.test", "group_id", "1"),
+				),
+			},
+			{
+				Config: config("ops-alerts"),
+				Check:  resource.TestCheckResourceAttr("roxywi_channel.test", "channel", "ops-alerts"),
+			},
+			{
+				ResourceName:      "roxywi_channel.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceChannelReportsUndecodableFields(t *testing.T) {
+	srv := newTestServer(t)
+	var channelPath string
+
+	config := testProviderConfig(srv) + `
+resource "roxywi_channel" "test" {
+  receiver = "slack"
+  channel  = "alerts"
+  group_id = 1
+  token    = "xoxb-token"
+}
+`
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: func(s *terraform.State) error {
+					channelPath = "api/channel/slack/" + s.RootModule().Resources["roxywi_channel.test"].Primary.ID
+					return nil
+				},
+			},
+			{
+				// A blank token is kept from the configuration, while a
+				// group ID Roxy-WI reports as a string is decoded.
+				PreConfig: func() {
+					srv.Set(channelPath, map[string]interface{}{"receiver": "slack", "channel": "alerts", "group_id": "1", "token": ""})
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				PreConfig: func() {
+					srv.Set(channelPath, map[string]interface{}{"receiver": "slack", "channel": "alerts", "group_id": "first"})
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`Unable to decode "group_id" from the Roxy-WI response`),
+			},
+		},
+	})
+}
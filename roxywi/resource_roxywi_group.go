@@ -2,21 +2,19 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"log"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 func resourceGroup() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceGroupCreate,
-		ReadWithoutTimeout:   resourceGroupRead,
-		UpdateWithoutTimeout: resourceGroupUpdate,
-		DeleteWithoutTimeout: resourceGroupDelete,
+		CreateContext: resourceGroupCreate,
+		ReadContext:   resourceGroupRead,
+		UpdateContext: resourceGroupUpdate,
+		DeleteContext: resourceGroupDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -46,71 +44,41 @@ func resourceGroup() *schema.Resource {
 }
 
 func resourceGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	name := d.Get(NameField).(string)
-	description := d.Get(DescriptionField).(string)
+	client := m.(*Config).API
 
-	requestBody := map[string]string{NameField: name, DescriptionField: description}
-	resp, err := client.doRequest("POST", "/api/group", requestBody)
+	id, err := client.CreateGroup(ctx, expandGroup(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Printf("API response: %s", resp)
-
-	// Assuming the response contains an ID field with the unique identifier
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result[IDField].(float64) // ID возвращается как число
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d", int(id))) // Преобразование ID в строку
+	d.SetId(id)
 	return resourceGroupRead(ctx, d, m)
 }
 
 func resourceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	// Implement API call to read the resource
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/group/%s", id), nil)
-	if err != nil {
+	group, err := client.GetGroup(ctx, id)
+	if err != nil && group == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	// Process response and set data
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	if name, ok := result[NameField].(string); ok {
-		d.Set(NameField, name)
-	}
+	d.Set(NameField, string(group.Name))
+	d.Set(DescriptionField, string(group.Description))
 
-	if description, ok := result[DescriptionField].(string); ok {
-		d.Set(DescriptionField, description)
-	}
-
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	requestBody := map[string]string{NameField: d.Get(NameField).(string)}
-	if d.HasChange(DescriptionField) {
-		requestBody[DescriptionField] = d.Get(DescriptionField).(string)
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/group/%s", id), requestBody)
-	if err != nil {
+	if err := client.UpdateGroup(ctx, id, expandGroup(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -118,15 +86,20 @@ func resourceGroupUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	// Implement API call to delete the resource
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/group/%s", id), nil)
-	if err != nil {
+	if err := client.DeleteGroup(ctx, id); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandGroup(d *schema.ResourceData) *api.Group {
+	return &api.Group{
+		Name:        api.String(d.Get(NameField).(string)),
+		Description: api.String(d.Get(DescriptionField).(string)),
+	}
+}
@@ -0,0 +1,126 @@
+package roxywi
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceGroup(t *testing.T) {
+	srv := newTestServer(t)
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_group", idPath("api/group")),
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "roxywi_group" "test" {
+  name        = "web"
+  description = "Web servers"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_group.test", idPath("api/group")),
+					resource.TestCheckResourceAttr("roxywi_group.test", "name", "web"),
+					resource.TestCheckResourceAttr("roxywi_group.test", "description", "Web servers"),
+				),
+			},
+			{
+				Config: testProviderConfig(srv) + `
+resource "roxywi_group" "test" {
+  name        = "web"
+  description = "Frontend servers"
+}
+`,
+				Check: resource.TestCheckResourceAttr("roxywi_group.test", "description", "Frontend servers"),
+			},
+			{
+				ResourceName:      "roxywi_group.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceGroupReportsUndecodableFields(t *testing.T) {
+	srv := newTestServer(t)
+	var id string
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "roxywi_group" "test" {
+  name = "web"
+}
+`,
+				Check: func(s *terraform.State) error {
+					id = s.RootModule().Resources["roxywi_group.test"].Primary.ID
+					return nil
+				},
+			},
+			{
+				PreConfig: func() {
+					srv.Set("api/group/"+id, map[string]interface{}{"group_id": id, "name": "web", "description": []interface{}{"Web"}})
+				},
+				Config: testProviderConfig(srv) + `
+resource "roxywi_group" "test" {
+  name = "web"
+}
+`,
+				ExpectError: regexp.MustCompile(`Unable to decode "description" from the Roxy-WI response`),
+			},
+			{
+				PreConfig: func() {
+					srv.Set("api/group/"+id, map[string]interface{}{"group_id": id, "name": "web", "description": ""})
+				},
+				Config: testProviderConfig(srv) + `
+resource "roxywi_group" "test" {
+  name = "web"
+}
+`,
+			},
+		},
+	})
+}
+
+func TestDataSourceGroup(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/group/7", map[string]interface{}{"group_id": "7", "name": "web", "description": "Web servers"})
+	srv.Set("api/group/8", map[string]interface{}{"group_id": 8, "name": []interface{}{"broken"}})
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_group" "by_name" {
+  name = "web"
+}
+
+data "roxywi_group" "by_id" {
+  id = "7"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.roxywi_group.by_name", "id", "7"),
+					resource.TestCheckResourceAttr("data.roxywi_group.by_name", "description", "Web servers"),
+					resource.TestCheckResourceAttr("data.roxywi_group.by_id", "name", "web"),
+				),
+			},
+			{
+				Config: testProviderConfig(srv) + `
+data "roxywi_group" "test" {
+  id = "8"
+}
+`,
+				ExpectError: regexp.MustCompile(`Unable to decode "name" from the Roxy-WI response`),
+			},
+		},
+	})
+}
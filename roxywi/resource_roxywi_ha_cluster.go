@@ -2,13 +2,13 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"sort"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"strings"
-	"time"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -25,10 +25,10 @@ const (
 
 func resourceHaCluster() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaClusterCreate,
-		ReadWithoutTimeout:   resourceHaClusterRead,
-		UpdateWithoutTimeout: resourceHaClusterUpdate,
-		DeleteWithoutTimeout: resourceHaClusterDelete,
+		CreateContext: resourceHaClusterCreate,
+		ReadContext:   resourceHaClusterRead,
+		UpdateContext: resourceHaClusterUpdate,
+		DeleteContext: resourceHaClusterDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -44,14 +44,16 @@ func resourceHaCluster() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			DescriptionField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Description of the HA Cluster.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Description of the HA Cluster.",
+				ValidateFunc: validateStoredText,
 			},
 			NameField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the HA Cluster.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Name of the HA Cluster.",
+				ValidateFunc: validateStoredText,
 			},
 			ReturnToMasterField: {
 				Type:        schema.TypeBool,
@@ -138,170 +140,153 @@ func resourceHaCluster() *schema.Resource {
 }
 
 func resourceHaClusterCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-	name := strings.ReplaceAll(d.Get(NameField).(string), "'", "")
-
-	services := d.Get(ServicesField).([]interface{})
-	servicesMap := make(map[string]map[string]interface{})
-
-	for _, service := range services {
-		serviceData := service.(map[string]interface{})
-		serviceName := serviceData[NameField].(string)
-
-		servicesMap[serviceName] = map[string]interface{}{
-			DockerField:  boolToInt(serviceData[DockerField].(bool)),
-			EnabledField: boolToInt(serviceData[EnabledField].(bool)),
-		}
-	}
-
-	servers := parseServersList(d.Get(ServersField).([]interface{}))
-	fmt.Printf("Servers: %+v\n", servers)
-
-	haCluster := map[string]interface{}{
-		DescriptionField:    description,
-		NameField:           name,
-		ReturnToMasterField: boolToInt(d.Get(ReturnToMasterField).(bool)),
-		ServersField:        servers,
-		ServicesField:       servicesMap,
-		SynFloodField:       boolToInt(d.Get(SynFloodField).(bool)),
-		UseSrcField:         boolToInt(d.Get(UseSrcField).(bool)),
-		VIPField:            d.Get(VIPField).(string),
-		VirtServerField:     boolToInt(d.Get(VirtServerField).(bool)),
-		ReconfigureField:    true,
-	}
+	haCluster := expandHaCluster(d)
+	haCluster.Reconfigure = true
 
-	jsonData, _ := json.Marshal(haCluster)
-	fmt.Printf("HA Cluster Data: %s\n", string(jsonData))
-
-	resp, err := client.doRequest("POST", "/api/ha/cluster", haCluster)
+	id, err := client.CreateHaCluster(ctx, haCluster)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d", int(id)))
+	d.SetId(id)
 	return resourceHaClusterRead(ctx, d, m)
 }
 
 func resourceHaClusterRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/ha/cluster/%s", id), nil)
-	if err != nil {
+	haCluster, err := client.GetHaCluster(ctx, id)
+	if err != nil && haCluster == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
+	d.Set(DescriptionField, string(haCluster.Description))
+	d.Set(NameField, string(haCluster.Name))
+	d.Set(ReturnToMasterField, bool(haCluster.ReturnMaster))
+	d.Set(ServersField, flattenHaClusterServers(haCluster.Servers))
+	d.Set(ServicesField, flattenHaClusterServices(d, haCluster.Services))
+	d.Set(SynFloodField, bool(haCluster.SynFlood))
+	d.Set(UseSrcField, bool(haCluster.UseSrc))
+	d.Set(VIPField, string(haCluster.VIP))
+	d.Set(VirtServerField, bool(haCluster.VirtServer))
+
+	return apiDiagnostics(err)
+}
 
-	servicesMap := result[ServicesField].(map[string]interface{})
-	var servicesList []map[string]interface{}
+func resourceHaClusterUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	id := d.Id()
 
-	for serviceName, serviceDetails := range servicesMap {
-		serviceData := serviceDetails.(map[string]interface{})
-		servicesList = append(servicesList, map[string]interface{}{
-			NameField:    serviceName,
-			DockerField:  intToBool(serviceData[DockerField].(float64)),
-			EnabledField: intToBool(serviceData[EnabledField].(float64)),
-		})
+	haCluster := expandHaCluster(d)
+	if d.HasChange(ReturnToMasterField) || d.HasChange(ServersField) || d.HasChange(ServicesField) || d.HasChange(UseSrcField) || d.HasChange(VIPField) {
+		haCluster.Reconfigure = true
 	}
 
-	servers, err := parseConfig(result[ServersField])
-	if err != nil {
+	if err := client.UpdateHaCluster(ctx, id, haCluster); err != nil {
 		return diag.FromErr(err)
 	}
-	serversResult := parseServersResult(servers)
-
-	description := strings.ReplaceAll(result[DescriptionField].(string), "'", "")
-	name := strings.ReplaceAll(result[NameField].(string), "'", "")
 
-	d.Set(DescriptionField, description)
-	d.Set(NameField, name)
-	d.Set(ReturnToMasterField, intToBool(result[ReturnToMasterField].(float64)))
-	d.Set(ServersField, serversResult)
-	d.Set(ServicesField, result[ServicesField])
-	d.Set(SynFloodField, intToBool(result[SynFloodField].(float64)))
-	d.Set(UseSrcField, intToBool(result[UseSrcField].(float64)))
-	d.Set(VIPField, result[VIPField])
-	d.Set(VirtServerField, intToBool(result[VirtServerField].(float64)))
-
-	return nil
+	return resourceHaClusterRead(ctx, d, m)
 }
 
-func resourceHaClusterUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+func resourceHaClusterDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
 	id := d.Id()
 
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-	name := strings.ReplaceAll(d.Get(NameField).(string), "'", "")
+	if err := client.DeleteHaCluster(ctx, id); err != nil {
+		return diag.FromErr(err)
+	}
 
-	services := d.Get(ServicesField).([]interface{})
-	servicesMap := make(map[string]map[string]interface{})
+	d.SetId("")
+	return nil
+}
 
-	for _, service := range services {
+func expandHaCluster(d *schema.ResourceData) *api.HaCluster {
+	services := make(map[string]api.HaClusterService)
+	for _, service := range d.Get(ServicesField).([]interface{}) {
 		serviceData := service.(map[string]interface{})
-		serviceName := serviceData[NameField].(string)
-
-		servicesMap[serviceName] = map[string]interface{}{
-			DockerField:  boolToInt(serviceData[DockerField].(bool)),
-			EnabledField: boolToInt(serviceData[EnabledField].(bool)),
+		services[serviceData[NameField].(string)] = api.HaClusterService{
+			Docker:  api.IntBool(serviceData[DockerField].(bool)),
+			Enabled: api.IntBool(serviceData[EnabledField].(bool)),
 		}
 	}
 
-	servers := parseServersList(d.Get(ServersField).([]interface{}))
-	fmt.Printf("Servers: %+v\n", servers)
-
-	haCluster := map[string]interface{}{
-		DescriptionField:    description,
-		NameField:           name,
-		ReturnToMasterField: boolToInt(d.Get(ReturnToMasterField).(bool)),
-		ServersField:        servers,
-		ServicesField:       servicesMap,
-		SynFloodField:       boolToInt(d.Get(SynFloodField).(bool)),
-		UseSrcField:         boolToInt(d.Get(UseSrcField).(bool)),
-		VIPField:            d.Get(VIPField).(string),
-		VirtServerField:     boolToInt(d.Get(VirtServerField).(bool)),
+	return &api.HaCluster{
+		Description:  api.String(d.Get(DescriptionField).(string)),
+		Name:         api.String(d.Get(NameField).(string)),
+		ReturnMaster: api.IntBool(d.Get(ReturnToMasterField).(bool)),
+		Servers:      expandHaClusterServers(d.Get(ServersField).([]interface{})),
+		Services:     services,
+		SynFlood:     api.IntBool(d.Get(SynFloodField).(bool)),
+		UseSrc:       api.IntBool(d.Get(UseSrcField).(bool)),
+		VIP:          api.String(d.Get(VIPField).(string)),
+		VirtServer:   api.IntBool(d.Get(VirtServerField).(bool)),
 	}
+}
 
-	jsonData, _ := json.Marshal(haCluster)
-	fmt.Printf("HA Cluster Data: %s\n", string(jsonData))
-
-	if d.HasChange(ReturnToMasterField) || d.HasChange(ServersField) || d.HasChange(ServicesField) || d.HasChange(UseSrcField) || d.HasChange(VIPField) {
-		haCluster[ReconfigureField] = true
+func expandHaClusterServers(list []interface{}) api.HaClusterServers {
+	var servers api.HaClusterServers
+	for _, server := range list {
+		serverData := server.(map[string]interface{})
+		servers = append(servers, api.HaClusterServer{
+			ID:     api.Int(serverData[IDField].(int)),
+			Eth:    api.String(serverData[EthField].(string)),
+			Master: api.IntBool(serverData[MasterField].(bool)),
+		})
 	}
+	return servers
+}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/ha/cluster/%s", id), haCluster)
-	if err != nil {
-		return diag.FromErr(err)
+func flattenHaClusterServers(servers api.HaClusterServers) []interface{} {
+	list := make([]interface{}, 0, len(servers))
+	for _, server := range servers {
+		list = append(list, map[string]interface{}{
+			EthField:    string(server.Eth),
+			IDField:     int(server.ID),
+			MasterField: bool(server.Master),
+		})
 	}
-
-	return resourceHaClusterRead(ctx, d, m)
+	return list
 }
 
-func resourceHaClusterDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	id := d.Id()
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/ha/cluster/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
+// flattenHaClusterServices converts the services map returned by Roxy-WI into
+// the services list. Roxy-WI does not keep an order, so services are listed
+// in the order of the configuration, followed by any others sorted by name.
+func flattenHaClusterServices(d *schema.ResourceData, services map[string]api.HaClusterService) []interface{} {
+	var names []string
+	seen := make(map[string]bool)
+	for _, service := range d.Get(ServicesField).([]interface{}) {
+		name := service.(map[string]interface{})[NameField].(string)
+		if _, ok := services[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
 	}
 
-	d.SetId("")
-	return nil
+	var others []string
+	for name := range services {
+		if !seen[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	result := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		result = append(result, map[string]interface{}{
+			NameField:    name,
+			DockerField:  bool(services[name].Docker),
+			EnabledField: bool(services[name].Enabled),
+		})
+	}
+	return result
 }
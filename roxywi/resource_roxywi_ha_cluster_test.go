@@ -0,0 +1,145 @@
+package roxywi
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceHaClusterVipAndUdpListener(t *testing.T) {
+	srv := newTestServer(t)
+	vipPath := func(rs *terraform.ResourceState) string {
+		return "api/ha/cluster/" + strings.Replace(rs.Primary.ID, "-vip-", "/vip/", 1)
+	}
+
+	config := func(weight string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_ha_cluster" "test" {
+  name        = "edge"
+  description = "Edge balancers"
+  vip         = "10.0.0.100"
+
+  servers {
+    id     = 1
+    eth    = "eth0"
+    master = true
+  }
+
+  servers {
+    id     = 2
+    eth    = "eth0"
+    master = false
+  }
+
+  services {
+    name    = "haproxy"
+    docker  = false
+    enabled = true
+  }
+}
+
+resource "roxywi_ha_cluster_vip" "test" {
+  cluster_id = roxywi_ha_cluster.test.id
+  vip        = "10.0.0.101"
+
+  servers {
+    id     = 1
+    eth    = "eth0"
+    master = true
+  }
+}
+
+resource "roxywi_udp_listener" "test" {
+  name        = "dns"
+  description = "DNS"
+  cluster_id  = roxywi_ha_cluster.test.id
+  vip         = roxywi_ha_cluster_vip.test.vip
+  port        = 53
+  lb_algo     = "rr"
+
+  config {
+    backend_ip = "10.0.1.10"
+    port       = 53
+    weight     = ` + weight + `
+  }
+}
+
+data "roxywi_udp_listener" "test" {
+  name = roxywi_udp_listener.test.name
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckDestroyed(srv, "roxywi_ha_cluster", idPath("api/ha/cluster")),
+			testCheckDestroyed(srv, "roxywi_ha_cluster_vip", vipPath),
+			testCheckDestroyed(srv, "roxywi_udp_listener", idPath("api/udp/listener")),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config("50"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_ha_cluster.test", idPath("api/ha/cluster")),
+					testCheckExists(srv, "roxywi_ha_cluster_vip.test", vipPath),
+					testCheckExists(srv, "roxywi_udp_listener.test", idPath("api/udp/listener")),
+					resource.TestCheckResourceAttr("roxywi_ha_cluster.test", "servers.#", "2"),
+					resource.TestCheckResourceAttr("roxywi_udp_listener.test", "config.0.weight", "50"),
+					resource.TestCheckResourceAttrPair("data.roxywi_udp_listener.test", "id", "roxywi_udp_listener.test", "id"),
+				),
+			},
+			{
+				Config: config("100"),
+				Check:  resource.TestCheckResourceAttr("roxywi_udp_listener.test", "config.0.weight", "100"),
+			},
+			{
+				ResourceName:      "roxywi_ha_cluster.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "roxywi_ha_cluster_vip.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "roxywi_udp_listener.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestResourceHaClusterMalformedField checks that a field Roxy-WI sends in an
+// unexpected form is reported on its attribute instead of failing the read
+// as a whole.
+func TestResourceHaClusterMalformedField(t *testing.T) {
+	srv := newTestServer(t)
+
+	config := testProviderConfig(srv) + `
+resource "roxywi_ha_cluster" "test" {
+  name        = "edge"
+  description = "Edge balancers"
+  vip         = "10.0.0.100"
+
+  servers {
+    id     = 1
+    eth    = "eth0"
+    master = true
+  }
+}
+`
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: append([]resource.TestStep{{Config: config}}, testMalformedFieldSteps(
+			srv, config, func() string { return "api/ha/cluster/1" }, "vip", []interface{}{"10.0.0.100"},
+			regexp.MustCompile(`(?s)Unable to decode "vip" from the Roxy-WI response.*vip\s+= "10\.0\.0\.100"`),
+		)...),
+	})
+}
@@ -2,20 +2,21 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"strconv"
+	"terraform-provider-roxywi/roxywi/api"
 	"time"
 )
 
 func resourceHaClusterVip() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaClusterVipCreate,
-		ReadWithoutTimeout:   resourceHaClusterVipRead,
-		UpdateWithoutTimeout: resourceHaClusterVipUpdate,
-		DeleteWithoutTimeout: resourceHaClusterVipDelete,
+		CreateContext: resourceHaClusterVipCreate,
+		ReadContext:   resourceHaClusterVipRead,
+		UpdateContext: resourceHaClusterVipUpdate,
+		DeleteContext: resourceHaClusterVipDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -87,101 +88,61 @@ func resourceHaClusterVip() *schema.Resource {
 }
 
 func resourceHaClusterVipCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	clusterId := d.Get(ClusterIdField).(int)
+	vip := expandHaClusterVip(d)
+	vip.Reconfigure = true
 
-	servers := parseServersList(d.Get(ServersField).([]interface{}))
-	fmt.Printf("Servers: %+v\n", servers)
-
-	haCluster := map[string]interface{}{
-		ClusterIdField:      clusterId,
-		ReturnToMasterField: boolToInt(d.Get(ReturnToMasterField).(bool)),
-		ServersField:        servers,
-		UseSrcField:         boolToInt(d.Get(UseSrcField).(bool)),
-		VIPField:            d.Get(VIPField).(string),
-		VirtServerField:     boolToInt(d.Get(VirtServerField).(bool)),
-		ReconfigureField:    true,
-	}
-
-	jsonData, _ := json.Marshal(haCluster)
-	fmt.Printf("HA Cluster VIPData: %s\n", string(jsonData))
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("/api/ha/cluster/%d/vip", clusterId), haCluster)
+	id, err := client.CreateHaClusterVip(ctx, vip)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d-vip-%d", clusterId, int(id)))
+	d.SetId(fmt.Sprintf("%d-vip-%s", int(vip.ClusterID), id))
 	return resourceHaClusterVipRead(ctx, d, m)
 }
 
 func resourceHaClusterVipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	fullId := d.Id()
 	clusterId, vipId, err := resourceParseId(fullId, "-vip-")
 	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/ha/cluster/%s/vip/%s", clusterId, vipId), nil)
+	clusterID, err := strconv.Atoi(clusterId)
 	if err != nil {
-		return diag.FromErr(err)
+		return diag.Errorf("invalid cluster ID %q in ID %s", clusterId, fullId)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	servers, err := parseConfig(result[ServersField])
-	if err != nil {
+	vip, err := client.GetHaClusterVip(ctx, clusterId, vipId)
+	if err != nil && vip == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
-	serversResult := parseServersResult(servers)
 
-	d.Set(ClusterIdField, clusterId)
-	d.Set(ReturnToMasterField, intToBool(result[ReturnToMasterField].(float64)))
-	d.Set(ServersField, serversResult)
-	d.Set(UseSrcField, intToBool(result[UseSrcField].(float64)))
-	d.Set(VIPField, result[VIPField])
-	d.Set(VirtServerField, intToBool(result[VirtServerField].(float64)))
+	d.Set(ClusterIdField, clusterID)
+	d.Set(ReturnToMasterField, bool(vip.ReturnMaster))
+	d.Set(ServersField, flattenHaClusterServers(vip.Servers))
+	d.Set(UseSrcField, bool(vip.UseSrc))
+	d.Set(VIPField, string(vip.VIP))
+	d.Set(VirtServerField, bool(vip.VirtServer))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceHaClusterVipUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	fullId := d.Id()
-	clusterId, vipId, err1 := resourceParseId(fullId, "-vip-")
-	if err1 != nil {
-		return diag.FromErr(err1)
-	}
-
-	servers := parseServersList(d.Get(ServersField).([]interface{}))
-
-	haCluster := map[string]interface{}{
-		ClusterIdField:      clusterId,
-		ReturnToMasterField: boolToInt(d.Get(ReturnToMasterField).(bool)),
-		ServersField:        servers,
-		VIPField:            d.Get(VIPField).(string),
-		VirtServerField:     boolToInt(d.Get(VirtServerField).(bool)),
-		UseSrcField:         boolToInt(d.Get(UseSrcField).(bool)),
+	_, vipId, err := resourceParseId(fullId, "-vip-")
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/ha/cluster/%s/vip/%s", clusterId, vipId), haCluster)
-	if err != nil {
+	if err := client.UpdateHaClusterVip(ctx, vipId, expandHaClusterVip(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -189,18 +150,28 @@ func resourceHaClusterVipUpdate(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceHaClusterVipDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	fullId := d.Id()
-	clusterId, vipId, err1 := resourceParseId(fullId, "-vip-")
-	if err1 != nil {
-		return diag.FromErr(err1)
+	clusterId, vipId, err := resourceParseId(fullId, "-vip-")
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/ha/cluster/%s/vip/%s", clusterId, vipId), nil)
-	if err != nil {
+	if err := client.DeleteHaClusterVip(ctx, clusterId, vipId); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandHaClusterVip(d *schema.ResourceData) *api.HaClusterVip {
+	return &api.HaClusterVip{
+		ClusterID:    api.Int(d.Get(ClusterIdField).(int)),
+		ReturnMaster: api.IntBool(d.Get(ReturnToMasterField).(bool)),
+		Servers:      expandHaClusterServers(d.Get(ServersField).([]interface{})),
+		UseSrc:       api.IntBool(d.Get(UseSrcField).(bool)),
+		VIP:          api.String(d.Get(VIPField).(string)),
+		VirtServer:   api.IntBool(d.Get(VirtServerField).(bool)),
+	}
+}
@@ -2,7 +2,6 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"strings"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -20,10 +20,10 @@ const (
 
 func resourceHaproxyList() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaproxyListCreate,
-		ReadWithoutTimeout:   resourceHaproxyListRead,
-		UpdateWithoutTimeout: resourceHaproxyListUpdate,
-		DeleteWithoutTimeout: resourceHaproxyListDelete,
+		CreateContext: resourceHaproxyListCreate,
+		ReadContext:   resourceHaproxyListRead,
+		UpdateContext: resourceHaproxyListUpdate,
+		DeleteContext: resourceHaproxyListDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -86,38 +86,24 @@ func resourceHaproxyList() *schema.Resource {
 }
 
 func resourceHaproxyListCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	requestBody := map[string]interface{}{
-		NameField:     d.Get(NameField),
-		ColorField:    d.Get(ColorField),
-		ContentField:  d.Get(ContentField),
-		ServerIpField: d.Get(ServerIpField),
-		ActionField:   d.Get(ActionField),
-		GroupIDField:  d.Get(GroupIDField),
-	}
-
-	resp, err := client.doRequest("POST", "api/service/haproxy/list", requestBody)
+	client := m.(*Config).API
+	unlock, err := lockHaproxyConfigByIP(ctx, client, d.Get(ServerIpField).(string))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	id, err := client.CreateHaproxyList(ctx, expandHaproxyList(d))
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%s", id))
+	d.SetId(id)
 	return resourceHaproxyListRead(ctx, d, m)
 }
 
 func resourceHaproxyListRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	parts := strings.Split(d.Id(), "-")
 	if len(parts) < 3 {
 		return diag.FromErr(fmt.Errorf("expected ID in the format 'group_id-color-list_name.lst', got: %s", d.Id()))
@@ -125,40 +111,34 @@ func resourceHaproxyListRead(ctx context.Context, d *schema.ResourceData, m inte
 	color := parts[1]
 	listName := parts[2]
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/list/%s/%s", listName, color), nil)
-	if err != nil {
+	list, err := client.GetHaproxyList(ctx, listName, color)
+	if err != nil && list == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
+	d.Set(NameField, string(list.Name))
+	d.Set(ServerIpField, string(list.ServerIP))
+	d.Set(ActionField, string(list.Action))
+	d.Set(ColorField, string(list.Color))
+	d.Set(ContentField, string(list.Content))
+	d.Set(GroupIDField, int(list.GroupID))
 
-	d.Set(NameField, result[NameField])
-	d.Set(ServerIpField, result[ServerIpField])
-	d.Set(ActionField, result[ActionField])
-	d.Set(ColorField, result[ColorField])
-	d.Set(ContentField, result[ContentField])
-	d.Set(GroupIDField, intFromInterface(result[GroupIDField]))
-
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceHaproxyListUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	requestBody := map[string]interface{}{
-		NameField:     d.Get(NameField),
-		ColorField:    d.Get(ColorField),
-		ContentField:  d.Get(ContentField),
-		ServerIpField: d.Get(ServerIpField),
-		ActionField:   d.Get(ActionField),
-		GroupIDField:  d.Get(GroupIDField),
+	client := m.(*Config).API
+	unlock, err := lockHaproxyConfigByIP(ctx, client, d.Get(ServerIpField).(string))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	_, err := client.doRequest("PUT", "api/service/haproxy/list", requestBody)
-	if err != nil {
+	if err = client.UpdateHaproxyList(ctx, expandHaproxyList(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -166,18 +146,28 @@ func resourceHaproxyListUpdate(ctx context.Context, d *schema.ResourceData, m in
 }
 
 func resourceHaproxyListDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	requestBody := map[string]interface{}{
-		NameField:    d.Get(NameField),
-		ColorField:   d.Get(ColorField),
-		GroupIDField: d.Get(GroupIDField),
+	client := m.(*Config).API
+	unlock, err := lockHaproxyConfigByIP(ctx, client, d.Get(ServerIpField).(string))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	_, err := client.doRequest("DELETE", "api/service/haproxy/list", requestBody)
-	if err != nil {
+	if err = client.DeleteHaproxyList(ctx, d.Get(NameField).(string), d.Get(ColorField).(string), d.Get(GroupIDField).(int)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandHaproxyList(d *schema.ResourceData) *api.HaproxyList {
+	return &api.HaproxyList{
+		Name:     api.String(d.Get(NameField).(string)),
+		Color:    api.String(d.Get(ColorField).(string)),
+		Content:  api.String(d.Get(ContentField).(string)),
+		ServerIP: api.String(d.Get(ServerIpField).(string)),
+		Action:   api.String(d.Get(ActionField).(string)),
+		GroupID:  api.Int(d.Get(GroupIDField).(int)),
+	}
+}
@@ -0,0 +1,85 @@
+package roxywi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+func TestResourceHaproxyList(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/server/1", map[string]interface{}{"id": 1, "hostname": "haproxy-1", "ip": "10.0.0.10"})
+	listPath := func(rs *terraform.ResourceState) string {
+		return "api/service/haproxy/list/" + rs.Primary.Attributes["name"] + "/" + rs.Primary.Attributes["color"]
+	}
+
+	config := func(content string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_haproxy_list" "test" {
+  name      = "office"
+  server_ip = "10.0.0.10"
+  color     = "white"
+  content   = "` + content + `"
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_haproxy_list", listPath),
+		Steps: []resource.TestStep{
+			{
+				Config: config(`10.1.0.1\n10.1.0.2`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_haproxy_list.test", listPath),
+					resource.TestCheckResourceAttr("roxywi_haproxy_list.test", "id", "0-white-office"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_list.test", "content", "10.1.0.1\n10.1.0.2"),
+				),
+			},
+			{
+				Config: config(`10.1.0.1`),
+				Check:  resource.TestCheckResourceAttr("roxywi_haproxy_list.test", "content", "10.1.0.1"),
+			},
+			{
+				ResourceName:      "roxywi_haproxy_list.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestResourceHaproxyListDeleteWithoutServerList checks that a list can be
+// deleted while the server list, used to find the configuration lock of the
+// server, is unavailable.
+func TestResourceHaproxyListDeleteWithoutServerList(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/server/1", map[string]interface{}{"id": 1, "hostname": "haproxy-1", "ip": "10.0.0.10"})
+	listPath := func(rs *terraform.ResourceState) string {
+		return "api/service/haproxy/list/" + rs.Primary.Attributes["name"] + "/" + rs.Primary.Attributes["color"]
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_haproxy_list", listPath),
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "roxywi_haproxy_list" "test" {
+  name      = "office"
+  server_ip = "10.0.0.10"
+  color     = "white"
+  content   = "10.1.0.1"
+}
+`,
+				Check: func(*terraform.State) error {
+					srv.InjectFault(roxywitest.ServerError(http.MethodGet, `^api/servers$`, 0))
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -2,21 +2,22 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 func resourceHaproxySectionBackend() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaproxySectionBackendCreate,
-		ReadWithoutTimeout:   resourceHaproxySectionBackendRead,
-		UpdateWithoutTimeout: resourceHaproxySectionBackendUpdate,
-		DeleteWithoutTimeout: resourceHaproxySectionBackendDelete,
+		CreateContext: resourceHaproxySectionBackendCreate,
+		ReadContext:   resourceHaproxySectionBackendRead,
+		UpdateContext: resourceHaproxySectionBackendUpdate,
+		DeleteContext: resourceHaproxySectionBackendDelete,
 		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
 			if err := validateModeAndOptions(d); err != nil {
 				return fmt.Errorf("error while validateModeAndOptions: %w", err)
@@ -187,216 +188,148 @@ func resourceHaproxySectionBackend() *schema.Resource {
 }
 
 func resourceHaproxySectionBackendCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	backends := parseBackendsServerList(d.Get(BackendServersField).([]interface{}))
-	acls := parseAclsList(d.Get(AclsField).([]interface{}))
-	headers := parseHeaderList(d.Get(HeadersField).([]interface{}))
-	circuitBreaking, errs := getSetMap(d, CircuitBreakingField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	serversCheck, errs := getSetMap(d, ServersCheckField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	ssl, errs := getSetMap(d, SslField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	healthCheck, errs := getSetMap(d, HealthCheckField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	cookie, errs := getSetMap(d, CookieField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-
-	requestBody := map[string]interface{}{
-		BackendServersField:  backends,
-		AclsField:            acls,
-		HeadersField:         headers,
-		NameField:            d.Get(NameField),
-		TypeField:            "backend",
-		ServerIdField:        d.Get(ServerIdField),
-		ActionField:          d.Get(ActionField),
-		BalanceField:         d.Get(BalanceField),
-		BlacklistField:       d.Get(BlacklistField),
-		WhitelistField:       d.Get(WhitelistField),
-		ModeField:            d.Get(ModeField),
-		CircuitBreakingField: circuitBreaking,
-		ServersCheckField:    serversCheck,
-		SslField:             ssl,
-		HealthCheckField:     healthCheck,
-		CookieField:          cookie,
-		CacheField:           d.Get(CacheField),
-		CompressionField:     d.Get(CompressionField),
-		ForwardForField:      d.Get(ForwardForField),
-		SslOffloadingField:   d.Get(SslOffloadingField),
-		RedisPatchField:      d.Get(RedisPatchField),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("api/service/haproxy/%d/section/backend", d.Get(ServerIdField)), requestBody)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := expandBackendSection(d)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
+	id, err := client.UpsertBackendSection(ctx, d.Get(ServerIdField).(int), section, true)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	d.SetId(fmt.Sprintf("%s", id))
+	d.SetId(id)
 	return resourceHaproxySectionBackendRead(ctx, d, m)
 }
 
 func resourceHaproxySectionBackendRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, sectionName, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, sectionName, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/%s/section/backend/%s", serverId, sectionName), nil)
-	if err != nil {
+	section, err := client.GetBackendSection(ctx, serverID, sectionName)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
+	d.Set(NameField, string(section.Name))
+	d.Set(BalanceField, string(section.Balance))
+	d.Set(ServerIdField, int(section.ServerID))
+	d.Set(ModeField, string(section.Mode))
+	d.Set(CacheField, bool(section.Cache))
+	d.Set(CompressionField, bool(section.Compression))
+	d.Set(ForwardForField, bool(section.ForwardFor))
+	d.Set(SslOffloadingField, bool(section.SslOffloading))
+	d.Set(RedisPatchField, bool(section.Redispatch))
+
+	for field, value := range map[string]api.ConfigMap{
+		CircuitBreakingField: section.CircuitBreaking,
+		ServersCheckField:    section.ServersCheck,
+		SslField:             section.Ssl,
+		HealthCheckField:     section.HealthCheck,
+		CookieField:          section.Cookie,
+	} {
+		if err := setTimeoutField(d, field, configMapOrNil(value)); err != nil {
+			tflog.Warn(ctx, "Unable to set section field", map[string]interface{}{"field": field, "error": err.Error()})
+		}
 	}
 
-	d.Set(NameField, result[NameField])
-	d.Set(BalanceField, result[BalanceField])
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(BlacklistField, result[BlacklistField])
-	d.Set(WhitelistField, result[WhitelistField])
-	d.Set(ModeField, result[ModeField])
-	d.Set(CacheField, result[CacheField])
-	d.Set(CompressionField, result[CompressionField])
-	d.Set(ForwardForField, result[ForwardForField])
-	d.Set(SslOffloadingField, result[SslOffloadingField])
-	d.Set(RedisPatchField, result[RedisPatchField])
+	r := newResponseDecoder(nil)
+	r.Set(d, BackendServersField, parseBackendServerResult(r, BackendServersField, section.BackendServers))
+	r.Set(d, AclsField, parseAclsServerResult(r, AclsField, section.Acls))
+	r.Set(d, HeadersField, parseHeadersResult(r, HeadersField, section.Headers))
 
-	if err = setTimeoutField(d, CircuitBreakingField, result[CircuitBreakingField]); err != nil {
-		fmt.Println("Error:", err)
-	}
-	if err = setTimeoutField(d, ServersCheckField, result[ServersCheckField]); err != nil {
-		fmt.Println("Error:", err)
-	}
-	if err = setTimeoutField(d, SslField, result[SslField]); err != nil {
-		fmt.Println("Error:", err)
-	}
-	if err = setTimeoutField(d, HealthCheckField, result[HealthCheckField]); err != nil {
-		fmt.Println("Error:", err)
-	}
+	return append(apiDiagnostics(err), r.Diagnostics()...)
+}
 
-	if err = setTimeoutField(d, CookieField, result[CookieField]); err != nil {
-		fmt.Println("Error:", err)
+func resourceHaproxySectionBackendUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	backendServers, err := parseConfig(result[BackendServersField])
+	section, err := expandBackendSection(d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	acl, err := parseConfig(result[AclsField])
-	if err != nil {
+	if _, err = client.UpsertBackendSection(ctx, d.Get(ServerIdField).(int), section, false); err != nil {
 		return diag.FromErr(err)
 	}
-	header, err := parseConfig(result[HeadersField])
+
+	return resourceHaproxySectionBackendRead(ctx, d, m)
+}
+
+func resourceHaproxySectionBackendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	backendServersList := parseBackendServerResult(backendServers)
-	acls := parseAclsServerResult(acl)
-	headers := parseHeadersResult(header)
-	d.Set(BackendServersField, backendServersList)
-	d.Set(AclsField, acls)
-	d.Set(HeadersField, headers)
-
-	_ = setTimeoutField(d, CircuitBreakingField, result[CircuitBreakingField])
+	if err = client.DeleteBackendSection(ctx, d.Get(ServerIdField).(int), d.Get(NameField).(string)); err != nil {
+		return diag.FromErr(err)
+	}
 
+	d.SetId("")
 	return nil
 }
 
-func resourceHaproxySectionBackendUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-	backends := parseBackendsServerList(d.Get(BackendServersField).([]interface{}))
-	acls := parseAclsList(d.Get(AclsField).([]interface{}))
-	header := parseHeaderList(d.Get(HeadersField).([]interface{}))
-	circuitBreaking, errs := getSetMap(d, CircuitBreakingField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	serversCheck, errs := getSetMap(d, ServersCheckField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	ssl, errs := getSetMap(d, SslField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-	healthCheck, errs := getSetMap(d, HealthCheckField)
-	if errs != nil {
-		return diag.FromErr(errs)
+func expandBackendSection(d *schema.ResourceData) (*api.BackendSection, error) {
+	circuitBreaking, err := getSetMap(d, CircuitBreakingField)
+	if err != nil {
+		return nil, err
 	}
-	cookie, errs := getSetMap(d, CookieField)
-	if errs != nil {
-		return diag.FromErr(errs)
+	serversCheck, err := getSetMap(d, ServersCheckField)
+	if err != nil {
+		return nil, err
 	}
-
-	requestBody := map[string]interface{}{
-		BackendServersField:  backends,
-		AclsField:            acls,
-		HeadersField:         header,
-		NameField:            d.Get(NameField),
-		TypeField:            "backend",
-		ServerIdField:        d.Get(ServerIdField),
-		ActionField:          d.Get(ActionField),
-		ModeField:            d.Get(ModeField),
-		CircuitBreakingField: circuitBreaking,
-		ServersCheckField:    serversCheck,
-		SslField:             ssl,
-		HealthCheckField:     healthCheck,
-		CookieField:          cookie,
-		CacheField:           d.Get(CacheField),
-		CompressionField:     d.Get(CompressionField),
-		ForwardForField:      d.Get(ForwardForField),
-		SslOffloadingField:   d.Get(SslOffloadingField),
-		RedisPatchField:      d.Get(RedisPatchField),
-		BalanceField:         d.Get(BalanceField),
+	ssl, err := getSetMap(d, SslField)
+	if err != nil {
+		return nil, err
 	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/haproxy/%d/section/backend/%s", serverId, sectionName), requestBody)
+	healthCheck, err := getSetMap(d, HealthCheckField)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
 	}
-
-	return resourceHaproxySectionBackendRead(ctx, d, m)
-}
-
-func resourceHaproxySectionBackendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("api/service/haproxy/%d/section/backend/%s", serverId, sectionName), nil)
+	cookie, err := getSetMap(d, CookieField)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
 	}
 
-	d.SetId("")
-	return nil
+	return &api.BackendSection{
+		Name:            api.String(d.Get(NameField).(string)),
+		Action:          api.String(d.Get(ActionField).(string)),
+		Balance:         api.String(d.Get(BalanceField).(string)),
+		Mode:            api.String(d.Get(ModeField).(string)),
+		BackendServers:  parseBackendsServerList(d.Get(BackendServersField).([]interface{})),
+		Acls:            parseAclsList(d.Get(AclsField).([]interface{})),
+		Headers:         parseHeaderList(d.Get(HeadersField).([]interface{})),
+		CircuitBreaking: circuitBreaking,
+		ServersCheck:    serversCheck,
+		Ssl:             ssl,
+		HealthCheck:     healthCheck,
+		Cookie:          cookie,
+		Cache:           api.Bool(d.Get(CacheField).(bool)),
+		Compression:     api.Bool(d.Get(CompressionField).(bool)),
+		ForwardFor:      api.Bool(d.Get(ForwardForField).(bool)),
+		SslOffloading:   api.Bool(d.Get(SslOffloadingField).(bool)),
+		Redispatch:      api.Bool(d.Get(RedisPatchField).(bool)),
+	}, nil
 }
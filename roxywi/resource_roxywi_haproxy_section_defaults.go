@@ -2,12 +2,12 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -24,9 +24,9 @@ const (
 
 func resourceHaproxySectionDefaults() *schema.Resource {
 	return &schema.Resource{
-		ReadWithoutTimeout:   resourceHaproxySectionDefaultsRead,
-		UpdateWithoutTimeout: resourceHaproxySectionDefaultsUpdate,
-		DeleteWithoutTimeout: resourceHaproxySectionDefaultsDelete,
+		ReadContext:   resourceHaproxySectionDefaultsRead,
+		UpdateContext: resourceHaproxySectionDefaultsUpdate,
+		DeleteContext: resourceHaproxySectionDefaultsDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -130,59 +130,57 @@ func resourceHaproxySectionDefaults() *schema.Resource {
 }
 
 func resourceHaproxySectionDefaultsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, _, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, _, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/%s/section/defaults", serverId), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := client.GetDefaultsSection(ctx, serverID)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	if err = setTimeoutField(d, "timeout", result["timeout"]); err != nil {
-		fmt.Println("Error:", err)
-	} else {
-		fmt.Println("Success:", d.Get("timeout"))
+	if err := setTimeoutField(d, TimeoutField, configMapOrNil(section.Timeout)); err != nil {
+		tflog.Warn(ctx, "Unable to set section field", map[string]interface{}{"field": TimeoutField, "error": err.Error()})
 	}
 
-	d.Set(MaxconnFiled, intFromInterface(result[MaxconnFiled]))
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(RetriesFiled, intFromInterface(result[RetriesFiled]))
-	d.Set(LogField, result[LogField])
-	d.Set(OptionFiled, result[OptionFiled])
-	d.Set(ActionField, result[ActionField])
+	d.Set(MaxconnFiled, int(section.Maxconn))
+	d.Set(ServerIdField, int(section.ServerID))
+	d.Set(RetriesFiled, int(section.Retries))
+	d.Set(LogField, string(section.Log))
+	d.Set(OptionFiled, string(section.Option))
+	d.Set(ActionField, string(section.Action))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceHaproxySectionDefaultsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	timeouts, errs := getTimeoutMap(d, "timeout")
-	if errs != nil {
-		return diag.FromErr(errs)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	requestBody := map[string]interface{}{
-		MaxconnFiled:  d.Get(MaxconnFiled),
-		LogField:      d.Get(LogField),
-		TypeField:     "defaults",
-		ServerIdField: d.Get(ServerIdField),
-		OptionFiled:   d.Get(OptionFiled),
-		RetriesFiled:  d.Get(RetriesFiled),
-		ActionField:   d.Get(ActionField),
-		TimeoutField:  timeouts,
+	timeouts, err := getTimeoutMap(d, TimeoutField)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/haproxy/%d/section/defaults", serverId), requestBody)
-	if err != nil {
+	section := &api.DefaultsSection{
+		Action:  api.String(d.Get(ActionField).(string)),
+		Log:     api.String(d.Get(LogField).(string)),
+		Maxconn: api.Int(d.Get(MaxconnFiled).(int)),
+		Option:  api.String(d.Get(OptionFiled).(string)),
+		Retries: api.Int(d.Get(RetriesFiled).(int)),
+		Timeout: timeouts,
+	}
+	if err = client.UpdateDefaultsSection(ctx, d.Get(ServerIdField).(int), section); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -2,21 +2,22 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 func resourceHaproxySectionFrontend() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaproxySectionFrontendCreate,
-		ReadWithoutTimeout:   resourceHaproxySectionFrontendRead,
-		UpdateWithoutTimeout: resourceHaproxySectionFrontendUpdate,
-		DeleteWithoutTimeout: resourceHaproxySectionFrontendDelete,
+		CreateContext: resourceHaproxySectionFrontendCreate,
+		ReadContext:   resourceHaproxySectionFrontendRead,
+		UpdateContext: resourceHaproxySectionFrontendUpdate,
+		DeleteContext: resourceHaproxySectionFrontendDelete,
 		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
 			if err := validateModeAndOptions(d); err != nil {
 				return fmt.Errorf("error while validateModeAndOptions: %w", err)
@@ -171,175 +172,132 @@ func resourceHaproxySectionFrontend() *schema.Resource {
 }
 
 func resourceHaproxySectionFrontendCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	binds := parseUserBindsList(d.Get(BindsField).([]interface{}))
-	acls := parseAclsList(d.Get(AclsField).([]interface{}))
-	headers := parseHeaderList(d.Get(HeadersField).([]interface{}))
-	ssl, errs := getSetMap(d, SslField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-
-	requestBody := map[string]interface{}{
-		BindsField:         binds,
-		UseBackendField:    d.Get(UseBackendField),
-		AclsField:          acls,
-		HeadersField:       headers,
-		NameField:          d.Get(NameField),
-		TypeField:          "frontend",
-		ServerIdField:      d.Get(ServerIdField),
-		ActionField:        d.Get(ActionField),
-		BlacklistField:     d.Get(BlacklistField),
-		WhitelistField:     d.Get(WhitelistField),
-		ModeField:          d.Get(ModeField),
-		SslField:           ssl,
-		CacheField:         d.Get(CacheField),
-		CompressionField:   d.Get(CompressionField),
-		ForwardForField:    d.Get(ForwardForField),
-		SslOffloadingField: d.Get(SslOffloadingField),
-		SlowAttackField:    d.Get(SlowAttackField),
-		AntiBotField:       d.Get(AntiBotField),
-		DdosField:          d.Get(DdosField),
-		WafField:           d.Get(WafField),
-		MaxconnFiled:       d.Get(MaxconnFiled),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("api/service/haproxy/%d/section/frontend", d.Get(ServerIdField)), requestBody)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := expandFrontendSection(d)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
+	id, err := client.UpsertFrontendSection(ctx, d.Get(ServerIdField).(int), section, true)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	d.SetId(fmt.Sprintf("%s", id))
+	d.SetId(id)
 	return resourceHaproxySectionFrontendRead(ctx, d, m)
 }
 
 func resourceHaproxySectionFrontendRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, sectionName, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, sectionName, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/%s/section/frontend/%s", serverId, sectionName), nil)
-	if err != nil {
+	section, err := client.GetFrontendSection(ctx, serverID, sectionName)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
+	d.Set(NameField, string(section.Name))
+	d.Set(UseBackendField, string(section.UseBackend))
+	d.Set(ServerIdField, int(section.ServerID))
+	d.Set(BlacklistField, string(section.Blacklist))
+	d.Set(WhitelistField, string(section.Whitelist))
+	d.Set(ModeField, string(section.Mode))
+	d.Set(CacheField, bool(section.Cache))
+	d.Set(CompressionField, bool(section.Compression))
+	d.Set(ForwardForField, bool(section.ForwardFor))
+	d.Set(SslOffloadingField, bool(section.SslOffloading))
+	d.Set(SlowAttackField, bool(section.SlowAttack))
+	d.Set(AntiBotField, bool(section.AntiBot))
+	d.Set(DdosField, bool(section.Ddos))
+	d.Set(WafField, bool(section.Waf))
+	d.Set(MaxconnFiled, int(section.Maxconn))
+
+	if err := setTimeoutField(d, SslField, configMapOrNil(section.Ssl)); err != nil {
+		tflog.Warn(ctx, "Unable to set section field", map[string]interface{}{"field": SslField, "error": err.Error()})
 	}
 
-	d.Set(NameField, result[NameField])
-	d.Set(UseBackendField, result[UseBackendField])
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(BlacklistField, result[BlacklistField])
-	d.Set(WhitelistField, result[WhitelistField])
-	d.Set(ModeField, result[ModeField])
-	d.Set(CacheField, result[CacheField])
-	d.Set(CompressionField, result[CompressionField])
-	d.Set(ForwardForField, result[ForwardForField])
-	d.Set(SslOffloadingField, result[SslOffloadingField])
-	d.Set(SlowAttackField, result[SlowAttackField])
-	d.Set(AntiBotField, result[AntiBotField])
-	d.Set(DdosField, result[DdosField])
-	d.Set(WafField, result[WafField])
-	d.Set(MaxconnFiled, result[MaxconnFiled])
+	r := newResponseDecoder(nil)
+	r.Set(d, BindsField, parseBindsResult(r, BindsField, section.Binds))
+	r.Set(d, AclsField, parseAclsServerResult(r, AclsField, section.Acls))
+	r.Set(d, HeadersField, parseHeadersResult(r, HeadersField, section.Headers))
 
-	if err = setTimeoutField(d, SslField, result[SslField]); err != nil {
-		fmt.Println("Error:", err)
-	}
+	return append(apiDiagnostics(err), r.Diagnostics()...)
+}
 
-	binds, err := parseConfig(result[BindsField])
+func resourceHaproxySectionFrontendUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	acl, err := parseConfig(result[AclsField])
+	section, err := expandFrontendSection(d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	header, err := parseConfig(result[HeadersField])
-	if err != nil {
+
+	if _, err = client.UpsertFrontendSection(ctx, d.Get(ServerIdField).(int), section, false); err != nil {
 		return diag.FromErr(err)
 	}
 
-	bindsList := parseBindsResult(binds)
-	acls := parseAclsServerResult(acl)
-	headers := parseHeadersResult(header)
-	d.Set(BindsField, bindsList)
-	d.Set(AclsField, acls)
-	d.Set(HeadersField, headers)
-
-	return nil
+	return resourceHaproxySectionFrontendRead(ctx, d, m)
 }
 
-func resourceHaproxySectionFrontendUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-	binds := parseUserBindsList(d.Get(BindsField).([]interface{}))
-	acls := parseAclsList(d.Get(AclsField).([]interface{}))
-	header := parseHeaderList(d.Get(HeadersField).([]interface{}))
-	ssl, errs := getSetMap(d, SslField)
-	if errs != nil {
-		return diag.FromErr(errs)
-	}
-
-	requestBody := map[string]interface{}{
-		BindsField:         binds,
-		UseBackendField:    d.Get(UseBackendField),
-		AclsField:          acls,
-		HeadersField:       header,
-		NameField:          d.Get(NameField),
-		TypeField:          "frontend",
-		ServerIdField:      d.Get(ServerIdField),
-		ActionField:        d.Get(ActionField),
-		BlacklistField:     d.Get(BlacklistField),
-		WhitelistField:     d.Get(WhitelistField),
-		ModeField:          d.Get(ModeField),
-		SslField:           ssl,
-		CacheField:         d.Get(CacheField),
-		CompressionField:   d.Get(CompressionField),
-		ForwardForField:    d.Get(ForwardForField),
-		SslOffloadingField: d.Get(SslOffloadingField),
-		SlowAttackField:    d.Get(SlowAttackField),
-		AntiBotField:       d.Get(AntiBotField),
-		DdosField:          d.Get(DdosField),
-		WafField:           d.Get(WafField),
-		MaxconnFiled:       d.Get(MaxconnFiled),
+func resourceHaproxySectionFrontendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/haproxy/%d/section/frontend/%s", serverId, sectionName), requestBody)
-	if err != nil {
+	if err = client.DeleteHaproxySection(ctx, d.Get(ServerIdField).(int), "frontend", d.Get(NameField).(string)); err != nil {
 		return diag.FromErr(err)
 	}
 
-	return resourceHaproxySectionFrontendRead(ctx, d, m)
+	d.SetId("")
+	return nil
 }
 
-func resourceHaproxySectionFrontendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("api/service/haproxy/%d/section/frontend/%s", serverId, sectionName), nil)
+func expandFrontendSection(d *schema.ResourceData) (*api.FrontendSection, error) {
+	ssl, err := getSetMap(d, SslField)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
 	}
 
-	d.SetId("")
-	return nil
+	return &api.FrontendSection{
+		Name:          api.String(d.Get(NameField).(string)),
+		Action:        api.String(d.Get(ActionField).(string)),
+		Mode:          api.String(d.Get(ModeField).(string)),
+		Blacklist:     api.String(d.Get(BlacklistField).(string)),
+		Whitelist:     api.String(d.Get(WhitelistField).(string)),
+		UseBackend:    api.String(d.Get(UseBackendField).(string)),
+		Binds:         parseUserBindsList(d.Get(BindsField).([]interface{})),
+		Acls:          parseAclsList(d.Get(AclsField).([]interface{})),
+		Headers:       parseHeaderList(d.Get(HeadersField).([]interface{})),
+		Ssl:           ssl,
+		Cache:         api.Bool(d.Get(CacheField).(bool)),
+		Compression:   api.Bool(d.Get(CompressionField).(bool)),
+		ForwardFor:    api.Bool(d.Get(ForwardForField).(bool)),
+		SslOffloading: api.Bool(d.Get(SslOffloadingField).(bool)),
+		SlowAttack:    api.Bool(d.Get(SlowAttackField).(bool)),
+		AntiBot:       api.Bool(d.Get(AntiBotField).(bool)),
+		Ddos:          api.Bool(d.Get(DdosField).(bool)),
+		Waf:           api.Bool(d.Get(WafField).(bool)),
+		Maxconn:       api.Int(d.Get(MaxconnFiled).(int)),
+	}, nil
 }
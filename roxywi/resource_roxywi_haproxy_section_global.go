@@ -2,12 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -23,9 +22,9 @@ const (
 
 func resourceHaproxySectionGlobal() *schema.Resource {
 	return &schema.Resource{
-		ReadWithoutTimeout:   resourceHaproxySectionGlobalRead,
-		UpdateWithoutTimeout: resourceHaproxySectionGlobalUpdate,
-		DeleteWithoutTimeout: resourceHaproxySectionGlobalDelete,
+		ReadContext:   resourceHaproxySectionGlobalRead,
+		UpdateContext: resourceHaproxySectionGlobalUpdate,
+		DeleteContext: resourceHaproxySectionGlobalDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -114,59 +113,58 @@ func resourceHaproxySectionGlobal() *schema.Resource {
 }
 
 func resourceHaproxySectionGlobalRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, _, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, _, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/%s/section/global", serverId), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := client.GetGlobalSection(ctx, serverID)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(MaxconnFiled, intFromInterface(result[MaxconnFiled]))
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(LogField, result[LogField])
-	d.Set(SocketFiled, result[SocketFiled])
-	d.Set(OptionFiled, result[OptionFiled])
-	d.Set(PidFileFiled, result[PidFileFiled])
-	d.Set(DaemonField, result[DaemonField])
-	d.Set(UserFiled, result[UserFiled])
-	d.Set(GroupNameField, result[GroupNameField])
-	d.Set(ChrootField, result[ChrootField])
-
-	return nil
+	d.Set(MaxconnFiled, int(section.Maxconn))
+	d.Set(ServerIdField, int(section.ServerID))
+	d.Set(LogField, []string(section.Log))
+	d.Set(SocketFiled, []string(section.Socket))
+	d.Set(OptionFiled, string(section.Option))
+	d.Set(PidFileFiled, string(section.PidFile))
+	d.Set(DaemonField, bool(section.Daemon))
+	d.Set(UserFiled, string(section.User))
+	d.Set(GroupNameField, string(section.Group))
+	d.Set(ChrootField, string(section.Chroot))
+
+	return apiDiagnostics(err)
 }
 
 func resourceHaproxySectionGlobalUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-
-	requestBody := map[string]interface{}{
-		MaxconnFiled:   d.Get(MaxconnFiled),
-		LogField:       d.Get(LogField),
-		TypeField:      "global",
-		ServerIdField:  d.Get(ServerIdField),
-		SocketFiled:    d.Get(SocketFiled),
-		OptionFiled:    d.Get(OptionFiled),
-		PidFileFiled:   d.Get(PidFileFiled),
-		DaemonField:    d.Get(DaemonField),
-		UserFiled:      d.Get(UserFiled),
-		GroupNameField: d.Get(GroupNameField),
-		ChrootField:    d.Get(ChrootField),
-		ActionField:    d.Get(ActionField),
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/haproxy/%d/section/global", serverId), requestBody)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
+
+	section := &api.GlobalSection{
+		Action:  api.String(d.Get(ActionField).(string)),
+		Log:     expandStringList(d.Get(LogField).([]interface{})),
+		Socket:  expandStringList(d.Get(SocketFiled).([]interface{})),
+		Maxconn: api.Int(d.Get(MaxconnFiled).(int)),
+		Option:  api.String(d.Get(OptionFiled).(string)),
+		PidFile: api.String(d.Get(PidFileFiled).(string)),
+		Daemon:  api.Bool(d.Get(DaemonField).(bool)),
+		User:    api.String(d.Get(UserFiled).(string)),
+		Group:   api.String(d.Get(GroupNameField).(string)),
+		Chroot:  api.String(d.Get(ChrootField).(string)),
+	}
+	if err = client.UpdateGlobalSection(ctx, d.Get(ServerIdField).(int), section); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return resourceHaproxySectionGlobalRead(ctx, d, m)
 }
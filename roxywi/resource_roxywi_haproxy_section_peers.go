@@ -2,12 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -17,10 +16,10 @@ const (
 
 func resourceHaproxySectionPeers() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaproxySectionPeersCreate,
-		ReadWithoutTimeout:   resourceHaproxySectionPeersRead,
-		UpdateWithoutTimeout: resourceHaproxySectionPeersUpdate,
-		DeleteWithoutTimeout: resourceHaproxySectionPeersDelete,
+		CreateContext: resourceHaproxySectionPeersCreate,
+		ReadContext:   resourceHaproxySectionPeersRead,
+		UpdateContext: resourceHaproxySectionPeersUpdate,
+		DeleteContext: resourceHaproxySectionPeersDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -82,86 +81,56 @@ func resourceHaproxySectionPeers() *schema.Resource {
 }
 
 func resourceHaproxySectionPeersCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	configs := parsePeersConfigList(d.Get(PeersField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		PeersField:    configs,
-		NameField:     d.Get(NameField),
-		TypeField:     "peers",
-		ServerIdField: d.Get(ServerIdField),
-		ActionField:   d.Get(ActionField),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("api/service/haproxy/%d/section/peers", d.Get(ServerIdField)), requestBody)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	id, err := client.UpsertPeersSection(ctx, d.Get(ServerIdField).(int), expandPeersSection(d), true)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%s", id))
+	d.SetId(id)
 	return resourceHaproxySectionPeersRead(ctx, d, m)
 }
 
 func resourceHaproxySectionPeersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, sectionName, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, sectionName, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/%s/section/peers/%s", serverId, sectionName), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := client.GetPeersSection(ctx, serverID, sectionName)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(NameField, result[NameField])
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(PeersField, result[PeersField])
-
-	config, err := parseConfig(result["peers"])
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	d.Set(NameField, string(section.Name))
+	d.Set(ServerIdField, int(section.ServerID))
 
-	configList := parsePeersConfigListResult(config)
-	d.Set(ConfigField, configList)
+	r := newResponseDecoder(nil)
+	r.Set(d, PeersField, parsePeersConfigListResult(r, PeersField, section.Peers))
 
-	return nil
+	return append(apiDiagnostics(err), r.Diagnostics()...)
 }
 
 func resourceHaproxySectionPeersUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	configs := parsePeersConfigList(d.Get(PeersField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		PeersField:    configs,
-		NameField:     d.Get(NameField),
-		TypeField:     "peers",
-		ServerIdField: d.Get(ServerIdField),
-		ActionField:   d.Get(ActionField),
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/haproxy/%d/section/peers/%s", serverId, sectionName), requestBody)
-	if err != nil {
+	if _, err = client.UpsertPeersSection(ctx, d.Get(ServerIdField).(int), expandPeersSection(d), false); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -169,15 +138,25 @@ func resourceHaproxySectionPeersUpdate(ctx context.Context, d *schema.ResourceDa
 }
 
 func resourceHaproxySectionPeersDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("api/service/haproxy/%d/section/peers/%s", serverId, sectionName), nil)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
+
+	if err = client.DeleteHaproxySection(ctx, d.Get(ServerIdField).(int), "peers", d.Get(NameField).(string)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandPeersSection(d *schema.ResourceData) *api.PeersSection {
+	return &api.PeersSection{
+		Name:   api.String(d.Get(NameField).(string)),
+		Action: api.String(d.Get(ActionField).(string)),
+		Peers:  parsePeersConfigList(d.Get(PeersField).([]interface{})),
+	}
+}
@@ -0,0 +1,266 @@
+package roxywi
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// sectionPath returns a path function for HAProxy sections of sectionType.
+func sectionPath(sectionType string) func(*terraform.ResourceState) string {
+	return func(rs *terraform.ResourceState) string {
+		return "api/service/haproxy/" + rs.Primary.Attributes["server_id"] + "/section/" + sectionType + "/" + rs.Primary.Attributes["name"]
+	}
+}
+
+func TestResourceHaproxySections(t *testing.T) {
+	srv := newTestServer(t)
+
+	config := func(port string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_haproxy_section_backend" "test" {
+  server_id = 1
+  name      = "web"
+  balance   = "roundrobin"
+
+  health_check {
+    check = "tcp-check"
+  }
+
+  backend_servers {
+    server     = "10.0.0.20"
+    port       = ` + port + `
+    port_check = ` + port + `
+  }
+}
+
+resource "roxywi_haproxy_section_frontend" "test" {
+  server_id = 1
+  name      = "web"
+  backends  = roxywi_haproxy_section_backend.test.name
+
+  binds {
+    ip   = "0.0.0.0"
+    port = 80
+  }
+
+  acls {
+    acl_if         = 1
+    acl_value      = "example.com"
+    acl_then       = 5
+    acl_then_value = "web"
+  }
+}
+
+resource "roxywi_haproxy_section_listen" "test" {
+  server_id = 1
+  name      = "stats"
+  mode      = "tcp"
+  balance   = "roundrobin"
+
+  binds {
+    port = 8404
+  }
+
+  backend_servers {
+    server     = "10.0.0.21"
+    port       = ` + port + `
+    port_check = ` + port + `
+  }
+}
+
+resource "roxywi_haproxy_section_peers" "test" {
+  server_id = 1
+  name      = "cluster"
+
+  peers {
+    name = "haproxy-1"
+    ip   = "10.0.0.10"
+    port = 10000
+  }
+}
+
+resource "roxywi_haproxy_section_user_list" "test" {
+  server_id       = 1
+  name            = "admins"
+  userlist_groups = ["ops"]
+
+  userlist_users {
+    user     = "alice"
+    password = "secret"
+    group    = "ops"
+  }
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckDestroyed(srv, "roxywi_haproxy_section_backend", sectionPath("backend")),
+			testCheckDestroyed(srv, "roxywi_haproxy_section_frontend", sectionPath("frontend")),
+			testCheckDestroyed(srv, "roxywi_haproxy_section_listen", sectionPath("listen")),
+			testCheckDestroyed(srv, "roxywi_haproxy_section_peers", sectionPath("peers")),
+			testCheckDestroyed(srv, "roxywi_haproxy_section_user_list", sectionPath("userlist")),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config("8080"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_haproxy_section_backend.test", sectionPath("backend")),
+					testCheckExists(srv, "roxywi_haproxy_section_frontend.test", sectionPath("frontend")),
+					testCheckExists(srv, "roxywi_haproxy_section_listen.test", sectionPath("listen")),
+					testCheckExists(srv, "roxywi_haproxy_section_peers.test", sectionPath("peers")),
+					testCheckExists(srv, "roxywi_haproxy_section_user_list.test", sectionPath("userlist")),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_backend.test", "id", "1-web"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_backend.test", "backend_servers.0.port", "8080"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_frontend.test", "binds.0.port", "80"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_listen.test", "binds.0.port", "8404"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_peers.test", "peers.0.name", "haproxy-1"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_user_list.test", "userlist_users.0.user", "alice"),
+				),
+			},
+			{
+				Config: config("8081"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_backend.test", "backend_servers.0.port", "8081"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_listen.test", "backend_servers.0.port", "8081"),
+				),
+			},
+			// The action is applied when the section is written and is not
+			// reported back.
+			{
+				ResourceName:            "roxywi_haproxy_section_backend.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"action"},
+			},
+			{
+				ResourceName:            "roxywi_haproxy_section_frontend.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"action"},
+			},
+			{
+				ResourceName:            "roxywi_haproxy_section_listen.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"action"},
+			},
+			{
+				ResourceName:            "roxywi_haproxy_section_peers.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"action"},
+			},
+			{
+				ResourceName:            "roxywi_haproxy_section_user_list.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"action"},
+			},
+		},
+	})
+}
+
+// TestResourceHaproxySectionBackendMalformedField checks that a field
+// Roxy-WI sends in an unexpected form is reported on its attribute instead of
+// failing the read as a whole.
+func TestResourceHaproxySectionBackendMalformedField(t *testing.T) {
+	srv := newTestServer(t)
+
+	config := testProviderConfig(srv) + `
+resource "roxywi_haproxy_section_backend" "test" {
+  server_id = 1
+  name      = "web"
+  balance   = "roundrobin"
+
+  backend_servers {
+    server     = "10.0.0.20"
+    port       = 8080
+    port_check = 8080
+  }
+}
+`
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: append([]resource.TestStep{{Config: config}}, testMalformedFieldSteps(
+			srv, config, func() string { return "api/service/haproxy/1/section/backend/web" }, "balance", map[string]interface{}{"algorithm": "roundrobin"},
+			regexp.MustCompile(`(?s)Unable to decode "balance" from the Roxy-WI response.*balance\s+= "roundrobin"`),
+		)...),
+	})
+}
+
+// Global and defaults sections always exist, so they are imported before
+// they are managed, and destroying them leaves the configuration in place.
+func TestResourceHaproxySectionsGlobalAndDefaults(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Set("api/service/haproxy/1/section/global", map[string]interface{}{
+		"server_id": 1, "chroot": "haproxy", "daemon": true, "group": "haproxy", "user": "haproxy",
+		"maxconn": 5000, "pidfile": "/var/run/haproxy.pid", "action": "save",
+		"log": []interface{}{"127.0.0.1 local2"}, "socket": []interface{}{"*:1999 level admin"},
+	})
+	srv.Set("api/service/haproxy/1/section/defaults", map[string]interface{}{
+		"server_id": 1, "maxconn": 5000, "log": "global", "retries": 3, "action": "save",
+		"timeout": map[string]interface{}{"check": 10, "client": 60, "connect": 10, "http_keep_alive": 10, "http_request": 10, "queue": 60, "server": 60},
+	})
+
+	config := func(maxconn string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_haproxy_section_global" "test" {
+  server_id = 1
+  maxconn   = ` + maxconn + `
+  log       = ["127.0.0.1 local2"]
+  socket    = ["*:1999 level admin"]
+}
+
+resource "roxywi_haproxy_section_defaults" "test" {
+  server_id = 1
+  maxconn   = ` + maxconn + `
+
+  timeout {
+    check = 10
+  }
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             config("5000"),
+				ResourceName:       "roxywi_haproxy_section_global.test",
+				ImportState:        true,
+				ImportStateId:      "1-global",
+				ImportStatePersist: true,
+			},
+			{
+				Config:             config("5000"),
+				ResourceName:       "roxywi_haproxy_section_defaults.test",
+				ImportState:        true,
+				ImportStateId:      "1-defaults",
+				ImportStatePersist: true,
+			},
+			{
+				Config: config("10000"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_global.test", "maxconn", "10000"),
+					resource.TestCheckResourceAttr("roxywi_haproxy_section_defaults.test", "maxconn", "10000"),
+					func(*terraform.State) error {
+						global, _ := srv.Get("api/service/haproxy/1/section/global")
+						defaults, _ := srv.Get("api/service/haproxy/1/section/defaults")
+						if global["maxconn"] != float64(10000) || defaults["maxconn"] != float64(10000) {
+							return fmt.Errorf("maxconn was not updated: global %v, defaults %v", global["maxconn"], defaults["maxconn"])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
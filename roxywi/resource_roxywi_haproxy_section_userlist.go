@@ -2,12 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -19,10 +18,10 @@ const (
 
 func resourceHaproxySectionUserlist() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceHaproxySectionUserlistCreate,
-		ReadWithoutTimeout:   resourceHaproxySectionUserlistRead,
-		UpdateWithoutTimeout: resourceHaproxySectionUserlistUpdate,
-		DeleteWithoutTimeout: resourceHaproxySectionUserlistDelete,
+		CreateContext: resourceHaproxySectionUserlistCreate,
+		ReadContext:   resourceHaproxySectionUserlistRead,
+		UpdateContext: resourceHaproxySectionUserlistUpdate,
+		DeleteContext: resourceHaproxySectionUserlistDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -92,89 +91,57 @@ func resourceHaproxySectionUserlist() *schema.Resource {
 }
 
 func resourceHaproxySectionUserlistCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	configs := parseUserListConfigList(d.Get(UserListField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		UserListField: configs,
-		NameField:     d.Get(NameField),
-		TypeField:     "userlist",
-		ServerIdField: d.Get(ServerIdField),
-		UserListGroup: d.Get(UserListGroup),
-		ActionField:   d.Get(ActionField),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("api/service/haproxy/%d/section/userlist", d.Get(ServerIdField)), requestBody)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	id, err := client.UpsertUserlistSection(ctx, d.Get(ServerIdField).(int), expandUserlistSection(d), true)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%s", id))
+	d.SetId(id)
 	return resourceHaproxySectionUserlistRead(ctx, d, m)
 }
 
 func resourceHaproxySectionUserlistRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, sectionName, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, sectionName, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/haproxy/%s/section/userlist/%s", serverId, sectionName), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := client.GetUserlistSection(ctx, serverID, sectionName)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(NameField, result[NameField])
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(UserListField, result[UserListField])
-	d.Set(UserListGroup, result[UserListGroup])
-
-	config, err := parseConfig(result["userlist_users"])
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	d.Set(NameField, string(section.Name))
+	d.Set(ServerIdField, int(section.ServerID))
+	d.Set(UserListGroup, []string(section.Groups))
 
-	configList := parseUserListConfigListResult(config)
-	d.Set(ConfigField, configList)
+	r := newResponseDecoder(nil)
+	r.Set(d, UserListField, parseUserListConfigListResult(r, UserListField, section.Users))
 
-	return nil
+	return append(apiDiagnostics(err), r.Diagnostics()...)
 }
 
 func resourceHaproxySectionUserlistUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	configs := parseUserListConfigList(d.Get(UserListField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		UserListField: configs,
-		NameField:     d.Get(NameField),
-		UserListGroup: d.Get(UserListGroup),
-		TypeField:     "userlist",
-		ServerIdField: d.Get(ServerIdField),
-		ActionField:   d.Get(ActionField),
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/haproxy/%d/section/userlist/%s", serverId, sectionName), requestBody)
-	if err != nil {
+	if _, err = client.UpsertUserlistSection(ctx, d.Get(ServerIdField).(int), expandUserlistSection(d), false); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -182,15 +149,26 @@ func resourceHaproxySectionUserlistUpdate(ctx context.Context, d *schema.Resourc
 }
 
 func resourceHaproxySectionUserlistDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("api/service/haproxy/%d/section/userlist/%s", serverId, sectionName), nil)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "haproxy", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
+
+	if err = client.DeleteHaproxySection(ctx, d.Get(ServerIdField).(int), "userlist", d.Get(NameField).(string)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandUserlistSection(d *schema.ResourceData) *api.UserlistSection {
+	return &api.UserlistSection{
+		Name:   api.String(d.Get(NameField).(string)),
+		Action: api.String(d.Get(ActionField).(string)),
+		Users:  parseUserListConfigList(d.Get(UserListField).([]interface{})),
+		Groups: expandStringList(d.Get(UserListGroup).([]interface{})),
+	}
+}
@@ -2,14 +2,12 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -22,10 +20,10 @@ const (
 
 func resourceServiceInstallation() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceServiceInstallationCreate,
-		ReadWithoutTimeout:   resourceServiceInstallationRead,
-		UpdateWithoutTimeout: resourceServiceInstallationUpdate,
-		DeleteWithoutTimeout: resourceServiceInstallationDelete,
+		CreateContext: resourceServiceInstallationCreate,
+		ReadContext:   resourceServiceInstallationRead,
+		UpdateContext: resourceServiceInstallationUpdate,
+		DeleteContext: resourceServiceInstallationDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -81,7 +79,7 @@ func resourceServiceInstallation() *schema.Resource {
 }
 
 func resourceServiceInstallationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	service, ok := d.Get("service").(string)
 	if !ok || service == "" {
@@ -93,40 +91,17 @@ func resourceServiceInstallationCreate(ctx context.Context, d *schema.ResourceDa
 		return diag.Errorf("server_id is required and must be an int")
 	}
 
-	autoStart := boolToInt(d.Get("auto_start").(bool))
-	checker := boolToInt(d.Get("checker").(bool))
-	metrics := boolToInt(d.Get("metrics").(bool))
-	docker := boolToInt(d.Get("docker").(bool))
-
-	payload := map[string]interface{}{
-		"auto_start": autoStart,
-		"checker":    checker,
-		"metrics":    metrics,
-		"docker":     docker,
-	}
-
-	url := fmt.Sprintf("/api/service/%s/%d/install", service, serverID)
-	resp, err := client.doRequest(http.MethodPost, url, payload)
+	id, err := client.InstallService(ctx, service, serverID, expandInstallation(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
-
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
 	d.SetId(id)
 
 	return resourceServiceInstallationRead(ctx, d, m)
 }
 
 func resourceServiceInstallationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	service, ok := d.Get("service").(string)
 	if !ok || service == "" {
@@ -138,40 +113,17 @@ func resourceServiceInstallationUpdate(ctx context.Context, d *schema.ResourceDa
 		return diag.Errorf("server_id is required and must be an int")
 	}
 
-	autoStart := boolToInt(d.Get("auto_start").(bool))
-	checker := boolToInt(d.Get("checker").(bool))
-	metrics := boolToInt(d.Get("metrics").(bool))
-	docker := boolToInt(d.Get("docker").(bool))
-
-	payload := map[string]interface{}{
-		"auto_start": autoStart,
-		"checker":    checker,
-		"metrics":    metrics,
-		"docker":     docker,
-	}
-
-	url := fmt.Sprintf("/api/service/%s/%d/install", service, serverID)
-	resp, err := client.doRequest(http.MethodPut, url, payload)
+	id, err := client.ReinstallService(ctx, service, serverID, expandInstallation(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
-
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
 	d.SetId(id)
 
 	return resourceServiceInstallationRead(ctx, d, m)
 }
 
 func resourceServiceInstallationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	// Получаем идентификатор ресурса
 	fullID := d.Id()
@@ -184,37 +136,32 @@ func resourceServiceInstallationRead(ctx context.Context, d *schema.ResourceData
 	id := parts[0]
 	service := parts[1]
 
-	url := fmt.Sprintf("/api/service/%s/%s/install", service, id)
-	resp, err := client.doRequest(http.MethodGet, url, nil)
-	if err != nil {
+	inst, err := client.GetInstallation(ctx, service, id)
+	if err != nil && inst == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
-
-	// Extracting the data and ensuring they are set correctly
-	d.Set(AutoStart, intToBool(result[AutoStart].(float64)))
-	d.Set(Checker, intToBool(result[Checker].(float64)))
-	d.Set(Metrics, intToBool(result[Metrics].(float64)))
-	d.Set(Docker, intToBool(result[Docker].(float64)))
-	d.Set(ServerField, result[ServerField].(float64))
-	d.Set(Service, result[Service].(string))
+	d.Set(AutoStart, bool(inst.AutoStart))
+	d.Set(Checker, bool(inst.Checker))
+	d.Set(Metrics, bool(inst.Metrics))
+	d.Set(Docker, bool(inst.Docker))
+	d.Set(ServerField, int(inst.ServerID))
+	d.Set(Service, string(inst.Service))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceServiceInstallationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	service := d.Get("service").(string)
 	serverID := d.Get("server_id").(int)
 
-	url := fmt.Sprintf("/api/service/%s/%d/install", service, serverID)
-	_, err := client.doRequest("DELETE", url, nil)
-	if err != nil {
+	if err := client.UninstallService(ctx, service, serverID); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -223,3 +170,12 @@ func resourceServiceInstallationDelete(ctx context.Context, d *schema.ResourceDa
 
 	return nil
 }
+
+func expandInstallation(d *schema.ResourceData) *api.Installation {
+	return &api.Installation{
+		AutoStart: api.IntBool(d.Get(AutoStart).(bool)),
+		Checker:   api.IntBool(d.Get(Checker).(bool)),
+		Metrics:   api.IntBool(d.Get(Metrics).(bool)),
+		Docker:    api.IntBool(d.Get(Docker).(bool)),
+	}
+}
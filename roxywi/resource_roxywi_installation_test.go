@@ -0,0 +1,50 @@
+package roxywi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceServiceInstallation(t *testing.T) {
+	srv := newTestServer(t)
+	installPath := func(*terraform.ResourceState) string { return "api/service/haproxy/1/install" }
+
+	config := func(metrics bool) string {
+		return testProviderConfig(srv) + fmt.Sprintf(`
+resource "roxywi_service_installation" "test" {
+  service    = "haproxy"
+  server_id  = 1
+  auto_start = true
+  metrics    = %t
+}
+`, metrics)
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_service_installation", installPath),
+		Steps: []resource.TestStep{
+			{
+				Config: config(false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_service_installation.test", installPath),
+					resource.TestCheckResourceAttr("roxywi_service_installation.test", "id", "1-haproxy"),
+					resource.TestCheckResourceAttr("roxywi_service_installation.test", "auto_start", "true"),
+					resource.TestCheckResourceAttr("roxywi_service_installation.test", "metrics", "false"),
+				),
+			},
+			{
+				Config: config(true),
+				Check:  resource.TestCheckResourceAttr("roxywi_service_installation.test", "metrics", "true"),
+			},
+			{
+				ResourceName:      "roxywi_service_installation.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
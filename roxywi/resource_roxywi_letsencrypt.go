@@ -2,13 +2,12 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -20,10 +19,11 @@ const (
 
 func resourceLetsencrypt() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceLetsencryptCreate,
-		ReadWithoutTimeout:   resourceLetsencryptRead,
-		UpdateWithoutTimeout: resourceLetsencryptUpdate,
-		DeleteWithoutTimeout: resourceLetsencryptDelete,
+		CreateContext: resourceLetsencryptCreate,
+		ReadContext:   resourceLetsencryptRead,
+		UpdateContext: resourceLetsencryptUpdate,
+		DeleteContext: resourceLetsencryptDelete,
+		CustomizeDiff: requireVersionDiff("roxywi_letsencrypt", letsencryptMinVersion),
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -35,7 +35,7 @@ func resourceLetsencrypt() *schema.Resource {
 			Delete: schema.DefaultTimeout(30 * time.Minute),
 		},
 
-		Description: "Manage Let's Encrypt certificates.",
+		Description: "Manage Let's Encrypt certificates. Requires Roxy-WI 8.1 or newer.",
 
 		Schema: map[string]*schema.Schema{
 			DescriptionField: {
@@ -90,78 +90,46 @@ func resourceLetsencrypt() *schema.Resource {
 }
 
 func resourceLetsencryptCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	requestBody := map[string]interface{}{
-		DescriptionField: d.Get(DescriptionField),
-		DomainsField:     d.Get(DomainsField),
-		ServerIdField:    d.Get(ServerIdField),
-		ApiTokenField:    d.Get(ApiTokenField),
-		ApiKeyField:      d.Get(ApiKeyField),
-		EmailField:       d.Get(EmailField),
-		TypeField:        d.Get(TypeField),
-	}
+	client := m.(*Config).API
 
-	resp, err := client.doRequest("POST", "api/service/letsencrypt", requestBody)
+	id, err := client.CreateCertificate(ctx, expandCertificate(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d", int(id)))
+	d.SetId(id)
 	return resourceLetsencryptRead(ctx, d, m)
 }
 
 func resourceLetsencryptRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/letsencrypt/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	cert, err := client.GetCertificate(ctx, id)
+	if err != nil && cert == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(DescriptionField, result[DescriptionField])
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(DomainsField, result[DomainsField])
-	d.Set(ApiTokenField, result[ApiTokenField])
-	d.Set(ApiKeyField, result[ApiKeyField])
-	d.Set(EmailField, result[EmailField])
-	d.Set(TypeField, result[TypeField])
+	d.Set(DescriptionField, string(cert.Description))
+	d.Set(ServerIdField, int(cert.ServerID))
+	d.Set(DomainsField, []string(cert.Domains))
+	d.Set(ApiTokenField, string(cert.APIToken))
+	d.Set(ApiKeyField, string(cert.APIKey))
+	d.Set(EmailField, string(cert.Email))
+	d.Set(TypeField, string(cert.Type))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceLetsencryptUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	requestBody := map[string]interface{}{
-		DescriptionField: d.Get(DescriptionField),
-		DomainsField:     d.Get(DomainsField),
-		ServerIdField:    d.Get(ServerIdField),
-		ApiTokenField:    d.Get(ApiTokenField),
-		ApiKeyField:      d.Get(ApiKeyField),
-		EmailField:       d.Get(EmailField),
-		TypeField:        d.Get(TypeField),
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/letsencrypt/%s", id), requestBody)
-	if err != nil {
+	if err := client.UpdateCertificate(ctx, id, expandCertificate(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -169,14 +137,25 @@ func resourceLetsencryptUpdate(ctx context.Context, d *schema.ResourceData, m in
 }
 
 func resourceLetsencryptDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/letsencrypt/%s", id), nil)
-	if err != nil {
+	if err := client.DeleteCertificate(ctx, id); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandCertificate(d *schema.ResourceData) *api.Certificate {
+	return &api.Certificate{
+		Description: api.String(d.Get(DescriptionField).(string)),
+		Domains:     expandStringList(d.Get(DomainsField).([]interface{})),
+		ServerID:    api.Int(d.Get(ServerIdField).(int)),
+		APIToken:    api.String(d.Get(ApiTokenField).(string)),
+		APIKey:      api.String(d.Get(ApiKeyField).(string)),
+		Email:       api.String(d.Get(EmailField).(string)),
+		Type:        api.String(d.Get(TypeField).(string)),
+	}
+}
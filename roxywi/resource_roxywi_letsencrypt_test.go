@@ -0,0 +1,87 @@
+package roxywi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+func testLetsencryptConfig(srv *roxywitest.Server, description string) string {
+	return testProviderConfig(srv) + fmt.Sprintf(`
+resource "roxywi_letsencrypt" "test" {
+  description = %q
+  email       = "admin@example.com"
+  domains     = ["example.com", "www.example.com"]
+  type        = "route53"
+  api_key     = "key"
+  api_token   = "token"
+  server_id   = 1
+}
+`, description)
+}
+
+func TestResourceLetsencrypt(t *testing.T) {
+	srv := newTestServer(t)
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_letsencrypt", idPath("api/service/letsencrypt")),
+		Steps: []resource.TestStep{
+			{
+				Config: testLetsencryptConfig(srv, "web"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_letsencrypt.test", idPath("api/service/letsencrypt")),
+					resource.TestCheckResourceAttr("roxywi_letsencrypt.test", "domains.#", "2"),
+					resource.TestCheckResourceAttr("roxywi_letsencrypt.test", "domains.1", "www.example.com"),
+				),
+			},
+			{
+				Config: testLetsencryptConfig(srv, "web and www"),
+				Check:  resource.TestCheckResourceAttr("roxywi_letsencrypt.test", "description", "web and www"),
+			},
+			{
+				ResourceName:      "roxywi_letsencrypt.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestResourceLetsencryptRequiresVersion runs against a release older than
+// letsencryptMinVersion: the certificate is refused at plan time without any
+// Let's Encrypt request being sent, while other resources are unaffected.
+func TestResourceLetsencryptRequiresVersion(t *testing.T) {
+	srv := newTestServer(t, roxywitest.WithVersion("7.4.2"))
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_group", idPath("api/group")),
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + testGroupConfig,
+				Check:  testCheckExists(srv, "roxywi_group.test", idPath("api/group")),
+			},
+			{
+				Config:      testLetsencryptConfig(srv, "web") + testGroupConfig,
+				ExpectError: regexp.MustCompile(`roxywi_letsencrypt requires Roxy-WI >= 8\.1\.0, but the server runs 7\.4\.2`),
+			},
+			{
+				Config: testProviderConfig(srv) + testGroupConfig,
+				Check: func(*terraform.State) error {
+					for _, req := range srv.Requests() {
+						if strings.HasPrefix(req.Path, "api/service/letsencrypt") {
+							return fmt.Errorf("unexpected request %s %s", req.Method, req.Path)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -2,21 +2,21 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 func resourceNginxSectionUpstream() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceNginxSectionUpstreamCreate,
-		ReadWithoutTimeout:   resourceNginxSectionUpstreamRead,
-		UpdateWithoutTimeout: resourceNginxSectionUpstreamUpdate,
-		DeleteWithoutTimeout: resourceNginxSectionUpstreamDelete,
+		CreateContext: resourceNginxSectionUpstreamCreate,
+		ReadContext:   resourceNginxSectionUpstreamRead,
+		UpdateContext: resourceNginxSectionUpstreamUpdate,
+		DeleteContext: resourceNginxSectionUpstreamDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -84,88 +84,58 @@ func resourceNginxSectionUpstream() *schema.Resource {
 }
 
 func resourceNginxSectionUpstreamCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	backends := parseNginxBackendsServerList(d.Get(BackendServersField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		BackendServersField: backends,
-		NameField:           d.Get(NameField),
-		ServerIdField:       d.Get(ServerIdField),
-		ActionField:         d.Get(ActionField),
-		BalanceField:        d.Get(BalanceField),
-		NginxKeepAlive:      d.Get(NginxKeepAlive),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("api/service/nginx/%d/section/upstream", d.Get(ServerIdField)), requestBody)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "nginx", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	id, err := client.UpsertUpstreamSection(ctx, d.Get(ServerIdField).(int), expandUpstreamSection(d), true)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	id, ok := result["id"].(string)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%s", id))
+	d.SetId(id)
 	return resourceNginxSectionUpstreamRead(ctx, d, m)
 }
 
 func resourceNginxSectionUpstreamRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId, sectionName, err := resourceSectionParseId(d.Id())
+	client := m.(*Config).API
+	serverID, sectionName, err := resourceSectionParseId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("api/service/nginx/%s/section/upstream/%s", serverId, sectionName), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	section, err := client.GetUpstreamSection(ctx, serverID, sectionName)
+	if err != nil && section == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(NameField, result[NameField])
-	d.Set(BalanceField, result[BalanceField])
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(NginxKeepAlive, intFromInterface(result[NginxKeepAlive]))
+	d.Set(NameField, string(section.Name))
+	d.Set(BalanceField, string(section.Balance))
+	d.Set(ServerIdField, int(section.ServerID))
+	d.Set(NginxKeepAlive, int(section.KeepAlive))
 
-	backendServers, err := parseConfig(result[BackendServersField])
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	r := newResponseDecoder(nil)
+	r.Set(d, BackendServersField, parseNginxBackendServerResult(r, BackendServersField, section.BackendServers))
 
-	backendServersList := parseNginxBackendServerResult(backendServers)
-	d.Set(BackendServersField, backendServersList)
-
-	return nil
+	return append(apiDiagnostics(err), r.Diagnostics()...)
 }
 
 func resourceNginxSectionUpstreamUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	backends := parseNginxBackendsServerList(d.Get(BackendServersField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		BackendServersField: backends,
-		NameField:           d.Get(NameField),
-		ServerIdField:       d.Get(ServerIdField),
-		ActionField:         d.Get(ActionField),
-		BalanceField:        d.Get(BalanceField),
-		NginxKeepAlive:      d.Get(NginxKeepAlive),
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "nginx", d.Get(ServerIdField))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	defer unlock()
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("api/service/nginx/%d/section/upstream/%s", serverId, sectionName), requestBody)
-	if err != nil {
+	if _, err = client.UpsertUpstreamSection(ctx, d.Get(ServerIdField).(int), expandUpstreamSection(d), false); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -173,15 +143,27 @@ func resourceNginxSectionUpstreamUpdate(ctx context.Context, d *schema.ResourceD
 }
 
 func resourceNginxSectionUpstreamDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	serverId := d.Get(ServerIdField)
-	sectionName := d.Get(NameField)
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("api/service/nginx/%d/section/upstream/%s", serverId, sectionName), nil)
+	client := m.(*Config).API
+	unlock, err := lockServerConfig(ctx, "nginx", d.Get(ServerIdField))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	defer unlock()
+
+	if err = client.DeleteUpstreamSection(ctx, d.Get(ServerIdField).(int), d.Get(NameField).(string)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandUpstreamSection(d *schema.ResourceData) *api.UpstreamSection {
+	return &api.UpstreamSection{
+		Name:           api.String(d.Get(NameField).(string)),
+		Action:         api.String(d.Get(ActionField).(string)),
+		Balance:        api.String(d.Get(BalanceField).(string)),
+		KeepAlive:      api.Int(d.Get(NginxKeepAlive).(int)),
+		BackendServers: parseNginxBackendsServerList(d.Get(BackendServersField).([]interface{})),
+	}
+}
@@ -0,0 +1,55 @@
+package roxywi
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceNginxSectionUpstream(t *testing.T) {
+	srv := newTestServer(t)
+	upstreamPath := func(rs *terraform.ResourceState) string {
+		return "api/service/nginx/" + rs.Primary.Attributes["server_id"] + "/section/upstream/" + rs.Primary.Attributes["name"]
+	}
+
+	config := func(balance string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_nginx_section_upstream" "test" {
+  server_id = 1
+  name      = "app"
+  balance   = "` + balance + `"
+
+  backend_servers {
+    server    = "10.0.0.20"
+    port      = 8080
+    max_fails = 3
+  }
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckDestroyed(srv, "roxywi_nginx_section_upstream", upstreamPath),
+		Steps: []resource.TestStep{
+			{
+				Config: config("ip_hash"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_nginx_section_upstream.test", upstreamPath),
+					resource.TestCheckResourceAttr("roxywi_nginx_section_upstream.test", "backend_servers.0.server", "10.0.0.20"),
+				),
+			},
+			{
+				Config: config("least_conn"),
+				Check:  resource.TestCheckResourceAttr("roxywi_nginx_section_upstream.test", "balance", "least_conn"),
+			},
+			{
+				ResourceName:            "roxywi_nginx_section_upstream.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"action"},
+			},
+		},
+	})
+}
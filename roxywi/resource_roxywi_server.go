@@ -2,13 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -21,10 +19,10 @@ const (
 
 func resourceServer() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceServerCreate,
-		ReadWithoutTimeout:   resourceServerRead,
-		UpdateWithoutTimeout: resourceServerUpdate,
-		DeleteWithoutTimeout: resourceServerDelete,
+		CreateContext: resourceServerCreate,
+		ReadContext:   resourceServerRead,
+		UpdateContext: resourceServerUpdate,
+		DeleteContext: resourceServerDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -45,9 +43,10 @@ func resourceServer() *schema.Resource {
 				Description: "Credentials ID.",
 			},
 			DescriptionField: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the server.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Description of the server.",
+				ValidateFunc: validateStoredText,
 			},
 			EnabledField: {
 				Type:        schema.TypeBool,
@@ -60,9 +59,10 @@ func resourceServer() *schema.Resource {
 				Description: "Group ID.",
 			},
 			HostnameField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Hostname of the server.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Hostname of the server.",
+				ValidateFunc: validateStoredText,
 			},
 			IPField: {
 				Type:        schema.TypeString,
@@ -80,86 +80,46 @@ func resourceServer() *schema.Resource {
 }
 
 func resourceServerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-	hostname := strings.ReplaceAll(d.Get(HostnameField).(string), "'", "")
-
-	server := map[string]interface{}{
-		CredIDField:      d.Get(CredIDField).(int),
-		DescriptionField: description,
-		EnabledField:     boolToInt(d.Get(EnabledField).(bool)),
-		GroupIDField:     d.Get(GroupIDField).(int),
-		HostnameField:    hostname,
-		IPField:          d.Get(IPField).(string),
-		PortField:        d.Get(PortField).(int),
-	}
+	client := m.(*Config).API
 
-	resp, err := client.doRequest("POST", "/api/server", server)
+	id, err := client.CreateServer(ctx, expandServer(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d", int(id)))
+	d.SetId(id)
 	return resourceServerRead(ctx, d, m)
 }
 
 func resourceServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/server/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	server, err := client.GetServer(ctx, id)
+	if err != nil && server == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(CredIDField, result[CredIDField])
-	description := strings.ReplaceAll(result[DescriptionField].(string), "'", "")
-	hostname := strings.ReplaceAll(result[HostnameField].(string), "'", "")
-	d.Set(DescriptionField, description)
-	d.Set(EnabledField, intToBool(result[EnabledField].(float64)))
-	d.Set(GroupIDField, result[GroupIDField])
-	d.Set(HostnameField, hostname)
-	d.Set(IPField, result[IPField])
-	d.Set(PortField, result[PortField])
+	d.Set(CredIDField, int(server.CredID))
+	d.Set(DescriptionField, string(server.Description))
+	d.Set(EnabledField, bool(server.Enabled))
+	d.Set(GroupIDField, int(server.GroupID))
+	d.Set(HostnameField, string(server.Hostname))
+	d.Set(IPField, string(server.IP))
+	d.Set(PortField, int(server.Port))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-	hostname := strings.ReplaceAll(d.Get(HostnameField).(string), "'", "")
-
-	server := map[string]interface{}{
-		CredIDField:      d.Get(CredIDField).(int),
-		DescriptionField: description,
-		EnabledField:     boolToInt(d.Get(EnabledField).(bool)),
-		GroupIDField:     d.Get(GroupIDField).(int),
-		HostnameField:    hostname,
-		IPField:          d.Get(IPField).(string),
-		PortField:        d.Get(PortField).(int),
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/server/%s", id), server)
-	if err != nil {
+	if err := client.UpdateServer(ctx, id, expandServer(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -167,14 +127,25 @@ func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 }
 
 func resourceServerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/server/%s", id), nil)
-	if err != nil {
+	if err := client.DeleteServer(ctx, id); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandServer(d *schema.ResourceData) *api.Server {
+	return &api.Server{
+		CredID:      api.Int(d.Get(CredIDField).(int)),
+		Description: api.String(d.Get(DescriptionField).(string)),
+		Enabled:     api.IntBool(d.Get(EnabledField).(bool)),
+		GroupID:     api.Int(d.Get(GroupIDField).(int)),
+		Hostname:    api.String(d.Get(HostnameField).(string)),
+		IP:          api.String(d.Get(IPField).(string)),
+		Port:        api.Int(d.Get(PortField).(int)),
+	}
+}
@@ -0,0 +1,148 @@
+package roxywi
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestResourceServer(t *testing.T) {
+	srv := newTestServer(t)
+
+	config := func(description string) string {
+		return testProviderConfig(srv) + `
+resource "roxywi_ssh_credential" "test" {
+  name     = "deploy"
+  group_id = 1
+  username = "deploy"
+  password = "secret"
+}
+
+resource "roxywi_server" "test" {
+  hostname    = "haproxy-1"
+  ip          = "10.0.0.10"
+  port        = 22
+  group_id    = 1
+  cred_id     = roxywi_ssh_credential.test.id
+  enabled     = true
+  description = "` + description + `"
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckDestroyed(srv, "roxywi_server", idPath("api/server")),
+			testCheckDestroyed(srv, "roxywi_ssh_credential", idPath("api/server/cred")),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config("Edge balancer"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_ssh_credential.test", idPath("api/server/cred")),
+					testCheckExists(srv, "roxywi_server.test", idPath("api/server")),
+					resource.TestCheckResourceAttr("roxywi_server.test", "hostname", "haproxy-1"),
+					resource.TestCheckResourceAttr("roxywi_server.test", "enabled", "true"),
+					resource.TestCheckResourceAttrPair("roxywi_server.test", "cred_id", "roxywi_ssh_credential.test", "id"),
+				),
+			},
+			{
+				Config: config("Internal balancer"),
+				Check:  resource.TestCheckResourceAttr("roxywi_server.test", "description", "Internal balancer"),
+			},
+			{
+				ResourceName:      "roxywi_server.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:            "roxywi_ssh_credential.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+		},
+	})
+}
+
+func TestResourceServerRemovedOutsideTerraform(t *testing.T) {
+	srv := newTestServer(t)
+
+	config := testProviderConfig(srv) + `
+resource "roxywi_server" "test" {
+  hostname = "haproxy-1"
+  ip       = "10.0.0.10"
+  port     = 22
+  group_id = 1
+  cred_id  = 1
+}
+`
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					srv.Delete("api/server/1")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// TestResourceServerMalformedField checks that a field Roxy-WI sends in an
+// unexpected form is reported on its attribute instead of failing the read
+// as a whole.
+func TestResourceServerMalformedField(t *testing.T) {
+	srv := newTestServer(t)
+
+	config := testProviderConfig(srv) + `
+resource "roxywi_server" "test" {
+  hostname = "haproxy-1"
+  ip       = "10.0.0.10"
+  port     = 22
+  group_id = 1
+  cred_id  = 1
+}
+`
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: append([]resource.TestStep{{Config: config}}, testMalformedFieldSteps(
+			srv, config, func() string { return "api/server/1" }, "port", []interface{}{22},
+			regexp.MustCompile(`(?s)Unable to decode "port" from the Roxy-WI response.*port\s+= 22`),
+		)...),
+	})
+}
+
+func TestResourceServerRejectsStrippedCharacters(t *testing.T) {
+	srv := newTestServer(t)
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "roxywi_server" "test" {
+  hostname    = "haproxy-1"
+  ip          = "10.0.0.10"
+  port        = 22
+  group_id    = 1
+  cred_id     = 1
+  description = "R&D edge balancer"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`expected value of description to not contain any of`),
+			},
+		},
+	})
+}
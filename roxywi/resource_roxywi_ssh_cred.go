@@ -2,11 +2,10 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"strings"
+	"terraform-provider-roxywi/roxywi/api"
 	"time"
 )
 
@@ -20,10 +19,10 @@ const (
 
 func resourceSSHCredential() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceSSHCredentialCreate,
-		ReadWithoutTimeout:   resourceSSHCredentialRead,
-		UpdateWithoutTimeout: resourceSSHCredentialUpdate,
-		DeleteWithoutTimeout: resourceSSHCredentialDelete,
+		CreateContext: resourceSSHCredentialCreate,
+		ReadContext:   resourceSSHCredentialRead,
+		UpdateContext: resourceSSHCredentialUpdate,
+		DeleteContext: resourceSSHCredentialDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -49,9 +48,10 @@ func resourceSSHCredential() *schema.Resource {
 				Description: "Key enabled. `true` you want use private_key instead of password, `false` otherwise.",
 			},
 			NameField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the credentials.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Name of the credentials.",
+				ValidateFunc: validateStoredText,
 			},
 			PasswordField: {
 				Type:        schema.TypeString,
@@ -60,9 +60,10 @@ func resourceSSHCredential() *schema.Resource {
 				Description: "Password for the SSH credentials.",
 			},
 			UsernameField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Username for the SSH credentials.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Username for the SSH credentials.",
+				ValidateFunc: validateStoredText,
 			},
 			PassPhraseField: {
 				Type:        schema.TypeString,
@@ -104,53 +105,25 @@ func resourceSSHCredential() *schema.Resource {
 }
 
 func resourceSSHCredentialCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	sshCred := map[string]interface{}{
-		GroupIDField:    d.Get(GroupIDField).(int),
-		KeyEnabledField: boolToInt(d.Get(KeyEnabledField).(bool)),
-		NameField:       strings.ReplaceAll(d.Get(NameField).(string), "'", ""),
-		PasswordField:   d.Get(PasswordField).(string),
-		UsernameField:   strings.ReplaceAll(d.Get(UsernameField).(string), "'", ""),
-		SharedField:     boolToInt(d.Get(SharedField).(bool)),
+	id, err := client.CreateSSHCredential(ctx, expandSSHCredential(d))
+	if id != "" {
+		d.SetId(id)
 	}
-
-	resp, err := client.doRequest("POST", "/api/server/cred", sshCred)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
+	key := &api.SSHKey{}
+	if passphrase := api.String(d.Get(PassPhraseField).(string)); passphrase != "" {
+		key.Passphrase = &passphrase
 	}
-	d.SetId(fmt.Sprintf("%d", int(id)))
-
-	status, ok := result["status"].(string)
-	if !ok || status != "Ok" {
-		return diag.Errorf("unexpected status in response: %v", result)
+	if privateKey := api.String(d.Get(PrivateKeyField).(string)); privateKey != "" {
+		key.PrivateKey = &privateKey
 	}
-
-	if d.Get(PassPhraseField).(string) != "" || d.Get(PrivateKeyField).(string) != "" {
-		patchData := map[string]interface{}{}
-		if d.Get(PassPhraseField).(string) != "" {
-			patchData[PassPhraseField] = d.Get(PassPhraseField).(string)
-		}
-		if d.Get(PrivateKeyField).(string) != "" {
-			patchData[PrivateKeyField] = d.Get(PrivateKeyField).(string)
-		}
-
-		resp, err := client.doRequest("PATCH", fmt.Sprintf("/api/server/cred/%s", d.Id()), patchData)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-
-		if err := json.Unmarshal(resp, &result); err != nil {
+	if key.Passphrase != nil || key.PrivateKey != nil {
+		if err := client.UpdateSSHKey(ctx, id, key); err != nil {
 			return diag.FromErr(err)
 		}
 	}
@@ -159,125 +132,89 @@ func resourceSSHCredentialCreate(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceSSHCredentialRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/server/cred/%s", id), nil)
-	if err != nil {
+	cred, err := client.GetSSHCredential(ctx, id)
+	if err != nil && cred == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	var resultArray []map[string]interface{}
-	if err := json.Unmarshal(resp, &resultArray); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
-
-	if len(resultArray) == 0 {
-		return diag.Errorf("empty array in response")
-	}
-	result := resultArray[0]
+	d.Set(GroupIDField, int(cred.GroupID))
+	d.Set(KeyEnabledField, bool(cred.KeyEnabled))
+	d.Set(NameField, string(cred.Name))
+	d.Set(PasswordField, string(cred.Password))
+	d.Set(UsernameField, string(cred.Username))
+	d.Set(PassPhraseField, string(cred.Passphrase))
+	d.Set(PrivateKeyField, string(cred.PrivateKey))
+	d.Set(SharedField, bool(cred.Shared))
 
-	d.Set(GroupIDField, result[GroupIDField])
-	d.Set(KeyEnabledField, intToBool(result[KeyEnabledField].(float64)))
-	name := strings.ReplaceAll(result[NameField].(string), "'", "")
-	d.Set(NameField, name)
-	d.Set(PasswordField, result[PasswordField])
-	username := strings.ReplaceAll(result[UsernameField].(string), "'", "")
-	d.Set(UsernameField, username)
-	d.Set(PassPhraseField, result[PassPhraseField])
-	d.Set(PrivateKeyField, result[PrivateKeyField])
-	d.Set(SharedField, intToBool(result[SharedField].(float64)))
-
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceSSHCredentialUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	sshCred := map[string]interface{}{
-		GroupIDField:    d.Get(GroupIDField).(int),
-		KeyEnabledField: boolToInt(d.Get(KeyEnabledField).(bool)),
-		NameField:       strings.ReplaceAll(d.Get(NameField).(string), "'", ""),
-		PasswordField:   d.Get(PasswordField).(string),
-		UsernameField:   strings.ReplaceAll(d.Get(UsernameField).(string), "'", ""),
-		SharedField:     boolToInt(d.Get(SharedField).(bool)),
-	}
-
+	cred := expandSSHCredential(d)
 	if d.Get(KeyEnabledField).(bool) {
 		privateKey := d.Get(PrivateKeyField).(string)
 		if privateKey == "" {
 			return diag.Errorf("`%s` must be provided when `%s` is true", PrivateKeyField, KeyEnabledField)
 		}
-		sshCred[PrivateKeyField] = privateKey
+		cred.PrivateKey = api.String(privateKey)
 	}
 
-	resp, err := client.doRequest("PUT", fmt.Sprintf("/api/server/cred/%s", id), sshCred)
+	acknowledged, err := client.UpdateSSHCredential(ctx, id, cred)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var statusResponse map[string]interface{}
-	if err := json.Unmarshal(resp, &statusResponse); err == nil {
-		if status, ok := statusResponse["status"]; ok && status == "Ok" {
-			patchData := map[string]interface{}{}
-			if d.HasChange(PassPhraseField) {
-				patchData[PassPhraseField] = d.Get(PassPhraseField).(string)
-			}
-			if d.HasChange(PrivateKeyField) {
-				patchData[PrivateKeyField] = d.Get(PrivateKeyField).(string)
-			}
-
-			if len(patchData) > 0 {
-				resp, err := client.doRequest("PATCH", fmt.Sprintf("/api/server/cred/%s", d.Id()), patchData)
-				if err != nil {
-					return diag.FromErr(err)
-				}
-
-				if err := json.Unmarshal(resp, &statusResponse); err != nil {
-					return diag.FromErr(err)
-				}
+	if acknowledged {
+		key := &api.SSHKey{}
+		if d.HasChange(PassPhraseField) {
+			passphrase := api.String(d.Get(PassPhraseField).(string))
+			key.Passphrase = &passphrase
+		}
+		if d.HasChange(PrivateKeyField) {
+			privateKey := api.String(d.Get(PrivateKeyField).(string))
+			key.PrivateKey = &privateKey
+		}
+		if key.Passphrase != nil || key.PrivateKey != nil {
+			if err := client.UpdateSSHKey(ctx, id, key); err != nil {
+				return diag.FromErr(err)
 			}
-
-			return resourceSSHCredentialRead(ctx, d, m)
 		}
 	}
 
-	var resultArray []map[string]interface{}
-	if err := json.Unmarshal(resp, &resultArray); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
-
-	if len(resultArray) == 0 {
-		return diag.Errorf("empty array in response")
-	}
-
 	return resourceSSHCredentialRead(ctx, d, m)
 }
 
 func resourceSSHCredentialDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("DELETE", fmt.Sprintf("/api/server/cred/%s", id), nil)
-	if err != nil {
+	if err := client.DeleteSSHCredential(ctx, id); err != nil {
 		return diag.FromErr(err)
 	}
 
-	if len(resp) == 0 {
-		d.SetId("")
-		return nil
-	}
-
-	var resultArray []map[string]interface{}
-	if err := json.Unmarshal(resp, &resultArray); err != nil {
-		return diag.Errorf("unexpected response format, could not unmarshal: %s", string(resp))
-	}
+	d.SetId("")
+	return nil
+}
 
-	if len(resultArray) > 0 {
-		d.SetId("")
-		return nil
+// expandSSHCredential returns the credential in d without its key, see
+// api.SSHCredential.
+func expandSSHCredential(d *schema.ResourceData) *api.SSHCredential {
+	return &api.SSHCredential{
+		GroupID:    api.Int(d.Get(GroupIDField).(int)),
+		KeyEnabled: api.IntBool(d.Get(KeyEnabledField).(bool)),
+		Name:       api.String(d.Get(NameField).(string)),
+		Password:   api.String(d.Get(PasswordField).(string)),
+		Username:   api.String(d.Get(UsernameField).(string)),
+		Shared:     api.IntBool(d.Get(SharedField).(bool)),
 	}
-
-	return diag.Errorf("unexpected response format during deletion")
 }
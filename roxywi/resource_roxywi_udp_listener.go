@@ -2,14 +2,13 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -36,10 +35,10 @@ const (
 
 func resourceUdpListener() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceUdpListenerCreate,
-		ReadWithoutTimeout:   resourceUdpListenerRead,
-		UpdateWithoutTimeout: resourceUdpListenerUpdate,
-		DeleteWithoutTimeout: resourceUdpListenerDelete,
+		CreateContext: resourceUdpListenerCreate,
+		ReadContext:   resourceUdpListenerRead,
+		UpdateContext: resourceUdpListenerUpdate,
+		DeleteContext: resourceUdpListenerDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -88,9 +87,10 @@ func resourceUdpListener() *schema.Resource {
 				},
 			},
 			DescriptionField: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "The description of the UDP listener.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The description of the UDP listener.",
+				ValidateFunc: validateStoredText,
 			},
 			GroupIdField: {
 				Type:        schema.TypeInt,
@@ -112,9 +112,10 @@ func resourceUdpListener() *schema.Resource {
 				}, false),
 			},
 			NameField: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The name of the UDP listener.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The name of the UDP listener.",
+				ValidateFunc: validateStoredText,
 			},
 			PortField: {
 				Type:         schema.TypeInt,
@@ -145,133 +146,65 @@ func resourceUdpListener() *schema.Resource {
 }
 
 func resourceUdpListenerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	clusterID := d.Get(ClusterIdField).(int)
-	serverID := d.Get(ServerIdField).(int)
-	vip := d.Get(VIPField).(string)
-
-	if err := checkVipExists(client, clusterID, serverID, vip); err != nil {
+	listener := expandUdpListener(d)
+	if err := checkVipExists(ctx, client, int(listener.ClusterID), int(listener.ServerID), string(listener.VIP)); err != nil {
 		return diag.FromErr(err)
 	}
+	listener.Reconfigure = true
 
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-	name := strings.ReplaceAll(d.Get(NameField).(string), "'", "")
-	groupID := d.Get(GroupIdField).(int)
-	lbAlgo := d.Get(LbAlgorithmField).(string)
-	port := d.Get(PortField).(int)
-
-	configs := parseConfigList(d.Get(ConfigField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		ClusterIdField:   clusterID,
-		ConfigField:      configs,
-		DescriptionField: description,
-		GroupIdField:     groupID,
-		LbAlgorithmField: lbAlgo,
-		NameField:        name,
-		PortField:        port,
-		ServerIdField:    serverID,
-		VIPField:         vip,
-		ReconfigureField: true,
-		IsCheckerFileld:  boolToInt(d.Get(IsCheckerFileld).(bool)),
-	}
-
-	resp, err := client.doRequest("POST", "/api/udp/listener", requestBody)
+	id, err := client.CreateUDPListener(ctx, listener)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"].(float64)
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	d.SetId(fmt.Sprintf("%d", int(id)))
+	d.SetId(id)
 	return resourceUdpListenerRead(ctx, d, m)
 }
 
 func resourceUdpListenerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/udp/listener/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	listener, err := client.GetUDPListener(ctx, id)
+	if err != nil && listener == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	d.Set(ClusterIdField, intFromInterface(result[ClusterIdField]))
-	description := strings.ReplaceAll(result[DescriptionField].(string), "'", "")
-	name := strings.ReplaceAll(result[NameField].(string), "'", "")
-	d.Set(DescriptionField, description)
-	d.Set(NameField, name)
-	d.Set(GroupIdField, intFromInterface(result[GroupIdField]))
-	d.Set(LbAlgorithmField, result[LbAlgorithmField])
-	d.Set(PortField, intFromInterface(result[PortField]))
-	d.Set(ServerIdField, intFromInterface(result[ServerIdField]))
-	d.Set(VIPField, result[VIPField])
-	d.Set(IsCheckerFileld, intToBool(result[IsCheckerFileld].(float64)))
-
-	config, err := parseConfig(result["config"])
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	d.Set(ClusterIdField, int(listener.ClusterID))
+	d.Set(DescriptionField, string(listener.Description))
+	d.Set(NameField, string(listener.Name))
+	d.Set(GroupIdField, int(listener.GroupID))
+	d.Set(LbAlgorithmField, string(listener.LbAlgo))
+	d.Set(PortField, int(listener.Port))
+	d.Set(ServerIdField, int(listener.ServerID))
+	d.Set(VIPField, string(listener.VIP))
+	d.Set(IsCheckerFileld, bool(listener.IsChecker))
 
-	configList := parseConfigResult(config)
-	d.Set(ConfigField, configList)
+	r := newResponseDecoder(nil)
+	r.Set(d, ConfigField, parseConfigResult(r, ConfigField, listener.Config))
 
-	return nil
+	return append(apiDiagnostics(err), r.Diagnostics()...)
 }
 
 func resourceUdpListenerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	clusterID := d.Get(ClusterIdField).(int)
-	serverID := d.Get(ServerIdField).(int)
-	vip := d.Get(VIPField).(string)
-
-	if err := checkVipExists(client, clusterID, serverID, vip); err != nil {
+	listener := expandUdpListener(d)
+	if err := checkVipExists(ctx, client, int(listener.ClusterID), int(listener.ServerID), string(listener.VIP)); err != nil {
 		return diag.FromErr(err)
 	}
-
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-	name := strings.ReplaceAll(d.Get(NameField).(string), "'", "")
-	groupID := d.Get(GroupIdField).(int)
-	lbAlgo := d.Get(LbAlgorithmField).(string)
-	port := d.Get(PortField).(int)
-
-	configs := parseConfigList(d.Get(ConfigField).([]interface{}))
-
-	requestBody := map[string]interface{}{
-		ClusterIdField:   clusterID,
-		ConfigField:      configs,
-		DescriptionField: description,
-		GroupIdField:     groupID,
-		LbAlgorithmField: lbAlgo,
-		NameField:        name,
-		PortField:        port,
-		ServerIdField:    serverID,
-		VIPField:         vip,
-		IsCheckerFileld:  boolToInt(d.Get(IsCheckerFileld).(bool)),
-	}
-
 	if d.HasChange(ConfigField) || d.HasChange(LbAlgorithmField) || d.HasChange(PortField) || d.HasChange(VIPField) {
-		requestBody[ReconfigureField] = true
+		listener.Reconfigure = true
 	}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/udp/listener/%s", id), requestBody)
-	if err != nil {
+	if err := client.UpdateUDPListener(ctx, id, listener); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -279,14 +212,28 @@ func resourceUdpListenerUpdate(ctx context.Context, d *schema.ResourceData, m in
 }
 
 func resourceUdpListenerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/udp/listener/%s", id), nil)
-	if err != nil {
+	if err := client.DeleteUDPListener(ctx, id); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandUdpListener(d *schema.ResourceData) *api.UDPListener {
+	return &api.UDPListener{
+		ClusterID:   api.Int(d.Get(ClusterIdField).(int)),
+		Config:      parseConfigList(d.Get(ConfigField).([]interface{})),
+		Description: api.String(d.Get(DescriptionField).(string)),
+		GroupID:     api.Int(d.Get(GroupIdField).(int)),
+		LbAlgo:      api.String(d.Get(LbAlgorithmField).(string)),
+		Name:        api.String(d.Get(NameField).(string)),
+		Port:        api.Int(d.Get(PortField).(int)),
+		ServerID:    api.Int(d.Get(ServerIdField).(int)),
+		VIP:         api.String(d.Get(VIPField).(string)),
+		IsChecker:   api.IntBool(d.Get(IsCheckerFileld).(bool)),
+	}
+}
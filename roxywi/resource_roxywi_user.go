@@ -2,10 +2,9 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 	"time"
 )
 
@@ -18,10 +17,10 @@ const (
 
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceUserCreate,
-		ReadWithoutTimeout:   resourceUserRead,
-		UpdateWithoutTimeout: resourceUserUpdate,
-		DeleteWithoutTimeout: resourceUserDelete,
+		CreateContext: resourceUserCreate,
+		ReadContext:   resourceUserRead,
+		UpdateContext: resourceUserUpdate,
+		DeleteContext: resourceUserDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -63,47 +62,22 @@ func resourceUser() *schema.Resource {
 }
 
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	user := map[string]interface{}{
-		UserEmailField:    d.Get(UserEmailField).(string),
-		UserEnabledField:  boolToInt(d.Get(UserEnabledField).(bool)),
-		UserPasswordField: d.Get(UserPasswordField).(string),
-		UserUsernameField: d.Get(UserUsernameField).(string),
-	}
-
-	resp, err := client.doRequest("POST", "/api/user", user)
+	id, err := client.CreateUser(ctx, expandUser(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	id, ok := result["id"]
-	if !ok {
-		return diag.Errorf("unable to extract user ID from response: %v", result)
-	}
-
-	switch v := id.(type) {
-	case string:
-		d.SetId(v)
-	case float64:
-		d.SetId(fmt.Sprintf("%.0f", v))
-	default:
-		return diag.Errorf("unexpected type for user ID: %T", id)
-	}
-
+	d.SetId(id)
 	return resourceUserRead(ctx, d, m)
 }
 
 func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/user/%s", d.Id()), nil)
-	if err != nil {
+	user, err := client.GetUser(ctx, d.Id())
+	if err != nil && user == nil {
 		if isNotFound(err) {
 			d.SetId("")
 			return nil
@@ -111,37 +85,18 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	if err := d.Set(UserEmailField, result[UserEmailField]); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set(UserEnabledField, intToBool(result[UserEnabledField].(float64))); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set(UserUsernameField, result[UserUsernameField]); err != nil {
-		return diag.FromErr(err)
-	}
+	d.Set(UserEmailField, string(user.Email))
+	d.Set(UserEnabledField, bool(user.Enabled))
+	d.Set(UserUsernameField, string(user.Username))
 	// Note: Password is not set here for security reasons
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	user := map[string]interface{}{
-		UserEmailField:    d.Get(UserEmailField).(string),
-		UserEnabledField:  boolToInt(d.Get(UserEnabledField).(bool)),
-		UserPasswordField: d.Get(UserPasswordField).(string),
-		UserUsernameField: d.Get(UserUsernameField).(string),
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/user/%s", d.Id()), user)
-	if err != nil {
+	if err := client.UpdateUser(ctx, d.Id(), expandUser(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -149,13 +104,21 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/user/%s", d.Id()), nil)
-	if err != nil {
+	if err := client.DeleteUser(ctx, d.Id()); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandUser(d *schema.ResourceData) *api.User {
+	return &api.User{
+		Email:    api.String(d.Get(UserEmailField).(string)),
+		Enabled:  api.IntBool(d.Get(UserEnabledField).(bool)),
+		Password: api.String(d.Get(UserPasswordField).(string)),
+		Username: api.String(d.Get(UserUsernameField).(string)),
+	}
+}
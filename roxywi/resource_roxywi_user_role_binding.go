@@ -2,7 +2,6 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -18,10 +18,10 @@ const (
 
 func resourceUserRoleBinding() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceUserRoleBindingCreate,
-		ReadWithoutTimeout:   resourceUserRoleBindingRead,
-		UpdateWithoutTimeout: resourceUserRoleBindingUpdate,
-		DeleteWithoutTimeout: resourceUserRoleBindingDelete,
+		CreateContext: resourceUserRoleBindingCreate,
+		ReadContext:   resourceUserRoleBindingRead,
+		UpdateContext: resourceUserRoleBindingUpdate,
+		DeleteContext: resourceUserRoleBindingDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -58,22 +58,12 @@ func resourceUserRoleBinding() *schema.Resource {
 }
 
 func resourceUserRoleBindingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	userID := d.Get(UserIDField).(int)
 	groupID := d.Get(GroupIDField).(int)
 
-	binding := map[string]interface{}{
-		RoleIDField: d.Get(RoleIDField).(int),
-	}
-
-	resp, err := client.doRequest("POST", fmt.Sprintf("/api/user/%d/groups/%d", userID, groupID), binding)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	if err := client.AddUserGroup(ctx, userID, groupID, d.Get(RoleIDField).(int)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -83,27 +73,15 @@ func resourceUserRoleBindingCreate(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceUserRoleBindingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	id := d.Id()
-	ids := strings.Split(id, "-")
-	if len(ids) != 2 {
-		return diag.Errorf("invalid ID format for user role binding: %s", id)
-	}
-	userIDStr := ids[0]
-	groupIDStr := ids[1]
+	client := m.(*Config).API
 
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	groupID, err := strconv.Atoi(groupIDStr)
+	userID, groupID, err := parseUserRoleBindingID(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/user/%d/groups", userID), nil)
-	if err != nil {
+	groups, err := client.ListUserGroups(ctx, userID)
+	if err != nil && !api.IsDecodeError(err) {
 		if isNotFound(err) {
 			d.SetId("")
 			return nil
@@ -111,48 +89,27 @@ func resourceUserRoleBindingRead(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 
-	var result []map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	var found bool
-	for _, group := range result {
-		if gid, ok := group["user_group_id"].(float64); ok && int(gid) == groupID {
-			if err := d.Set(UserIDField, userID); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set(RoleIDField, group["user_role_id"]); err != nil {
-				return diag.FromErr(err)
-			}
-			if err := d.Set(GroupIDField, groupID); err != nil {
-				return diag.FromErr(err)
-			}
-			found = true
-			break
+	for i, group := range groups {
+		if int(group.GroupID) != groupID {
+			continue
 		}
+		d.Set(UserIDField, userID)
+		d.Set(RoleIDField, int(group.RoleID))
+		d.Set(GroupIDField, groupID)
+		return apiDiagnostics(api.ItemError(err, i))
 	}
 
-	if !found {
-		d.SetId("")
-		return nil
-	}
-
+	d.SetId("")
 	return nil
 }
 
 func resourceUserRoleBindingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
 	userID := d.Get(UserIDField).(int)
 	groupID := d.Get(GroupIDField).(int)
 
-	binding := map[string]interface{}{
-		RoleIDField: d.Get(RoleIDField).(int),
-	}
-
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/user/%d/groups/%d", userID, groupID), binding)
-	if err != nil {
+	if err := client.UpdateUserGroup(ctx, userID, groupID, d.Get(RoleIDField).(int)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -160,21 +117,35 @@ func resourceUserRoleBindingUpdate(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceUserRoleBindingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 
-	id := d.Id()
-	ids := strings.Split(id, "-")
-	if len(ids) != 2 {
-		return diag.Errorf("invalid ID format for user role binding: %s", id)
+	userID, groupID, err := parseUserRoleBindingID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
 	}
-	userIDStr := ids[0]
-	groupIDStr := ids[1]
 
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/user/%s/groups/%s", userIDStr, groupIDStr), nil)
-	if err != nil {
+	if err := client.RemoveUserGroup(ctx, userID, groupID); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+// parseUserRoleBindingID splits the ID of a binding, "<user_id>-<group_id>".
+func parseUserRoleBindingID(id string) (int, int, error) {
+	ids := strings.Split(id, "-")
+	if len(ids) != 2 {
+		return 0, 0, fmt.Errorf("invalid ID format for user role binding: %s", id)
+	}
+
+	userID, err := strconv.Atoi(ids[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	groupID, err := strconv.Atoi(ids[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return userID, groupID, nil
+}
@@ -0,0 +1,78 @@
+package roxywi
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceUserAndRoleBinding(t *testing.T) {
+	srv := newTestServer(t)
+	bindingPath := func(rs *terraform.ResourceState) string {
+		return "api/user/" + rs.Primary.Attributes["user_id"] + "/groups/" + rs.Primary.Attributes["group_id"]
+	}
+
+	config := func(role string) string {
+		return testProviderConfig(srv) + `
+data "roxywi_user_role" "all" {}
+
+resource "roxywi_group" "test" {
+  name = "web"
+}
+
+data "roxywi_group" "test" {
+  name = roxywi_group.test.name
+}
+
+resource "roxywi_user" "test" {
+  username = "alice"
+  email    = "alice@example.com"
+  password = "secret"
+  enabled  = true
+}
+
+resource "roxywi_user_role_binding" "test" {
+  user_id  = roxywi_user.test.id
+  group_id = data.roxywi_group.test.id
+  role_id  = [for r in data.roxywi_user_role.all.roles : r.role_id if r.name == "` + role + `"][0]
+}
+`
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckDestroyed(srv, "roxywi_user", idPath("api/user")),
+			testCheckDestroyed(srv, "roxywi_user_role_binding", bindingPath),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config("admin"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckExists(srv, "roxywi_user.test", idPath("api/user")),
+					testCheckExists(srv, "roxywi_user_role_binding.test", bindingPath),
+					resource.TestCheckResourceAttr("data.roxywi_user_role.all", "roles.#", "4"),
+					resource.TestCheckResourceAttrPair("data.roxywi_group.test", "id", "roxywi_group.test", "id"),
+					resource.TestCheckResourceAttr("roxywi_user.test", "email", "alice@example.com"),
+					resource.TestCheckResourceAttr("roxywi_user_role_binding.test", "role_id", "2"),
+				),
+			},
+			{
+				Config: config("guest"),
+				Check:  resource.TestCheckResourceAttr("roxywi_user_role_binding.test", "role_id", "4"),
+			},
+			{
+				ResourceName:            "roxywi_user.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+			{
+				ResourceName:      "roxywi_user_role_binding.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -2,14 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -22,10 +19,10 @@ const (
 
 func resourceBackupFs() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceBackupFsCreate,
-		ReadWithoutTimeout:   resourceBackupFsRead,
-		UpdateWithoutTimeout: resourceBackupFsUpdate,
-		DeleteWithoutTimeout: resourceBackupFsDelete,
+		CreateContext: resourceBackupFsCreate,
+		ReadContext:   resourceBackupFsRead,
+		UpdateContext: resourceBackupFsUpdate,
+		DeleteContext: resourceBackupFsDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -46,9 +43,10 @@ func resourceBackupFs() *schema.Resource {
 				Description: "Credential ID.",
 			},
 			DescriptionField: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the backup.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Description of the backup.",
+				ValidateFunc: validateStoredText,
 			},
 			RPathField: {
 				Type:        schema.TypeString,
@@ -80,98 +78,46 @@ func resourceBackupFs() *schema.Resource {
 }
 
 func resourceBackupFsCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-
-	backup := map[string]interface{}{
-		CredIDField:      d.Get(CredIDField).(int),
-		DescriptionField: description,
-		RPathField:       d.Get(RPathField).(string),
-		RServerField:     d.Get(RServerField).(string),
-		ServerField:      d.Get(ServerField).(int),
-		TimeField:        d.Get(TimeField).(string),
-		TypeField:        d.Get(TypeField).(string),
-	}
+	client := m.(*Config).API
 
-	resp, err := client.doRequest("POST", "/api/server/backup/fs", backup)
+	id, err := client.CreateFsBackup(ctx, expandBackupFs(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	// Добавляем больше логирования для отладки
-	fmt.Printf("Response result: %v\n", result)
-
-	id, ok := result["id"]
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	// Проверка типа id и приведение к строке
-	var idStr string
-	switch v := id.(type) {
-	case string:
-		idStr = v
-	case int:
-		idStr = strconv.Itoa(v)
-	case float64:
-		idStr = strconv.FormatFloat(v, 'f', 0, 64)
-	default:
-		return diag.Errorf("unsupported type for ID: %T", v)
-	}
-
-	d.SetId(idStr)
+	d.SetId(id)
 	return resourceBackupFsRead(ctx, d, m)
 }
 
 func resourceBackupFsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/server/backup/fs/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	backup, err := client.GetFsBackup(ctx, id)
+	if err != nil && backup == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	description := strings.ReplaceAll(result[DescriptionField].(string), "'", "")
-
-	d.Set(CredIDField, result[CredIDField])
-	d.Set(DescriptionField, description)
-	d.Set(RPathField, result[RPathField])
-	d.Set(RServerField, result[RServerField])
-	d.Set(ServerField, result[ServerField])
-	d.Set(TimeField, result[TimeField])
-	d.Set(TypeField, result[TypeField])
+	d.Set(CredIDField, int(backup.CredID))
+	d.Set(DescriptionField, string(backup.Description))
+	d.Set(RPathField, string(backup.RPath))
+	d.Set(RServerField, string(backup.RServer))
+	d.Set(ServerField, int(backup.ServerID))
+	d.Set(TimeField, string(backup.Time))
+	d.Set(TypeField, string(backup.Type))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceBackupFsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-
-	backup := map[string]interface{}{
-		CredIDField:      d.Get(CredIDField).(int),
-		DescriptionField: description,
-		RPathField:       d.Get(RPathField).(string),
-		RServerField:     d.Get(RServerField).(string),
-		ServerField:      d.Get(ServerField).(int),
-		TimeField:        d.Get(TimeField).(string),
-		TypeField:        d.Get(TypeField).(string),
-	}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/server/backup/fs/%s", id), backup)
-	if err != nil {
+	if err := client.UpdateFsBackup(ctx, id, expandBackupFs(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -179,20 +125,25 @@ func resourceBackupFsUpdate(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func resourceBackupFsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	// Подготовка данных для удаления
-	deleteData := map[string]interface{}{
-		ServerField: d.Get(ServerField).(int),
-		CredIDField: d.Get(CredIDField).(int),
-	}
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/server/backup/fs/%s", id), deleteData)
-	if err != nil {
+	if err := client.DeleteFsBackup(ctx, id, d.Get(ServerField).(int), d.Get(CredIDField).(int)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandBackupFs(d *schema.ResourceData) *api.FsBackup {
+	return &api.FsBackup{
+		CredID:      api.Int(d.Get(CredIDField).(int)),
+		Description: api.String(d.Get(DescriptionField).(string)),
+		RPath:       api.String(d.Get(RPathField).(string)),
+		RServer:     api.String(d.Get(RServerField).(string)),
+		ServerID:    api.Int(d.Get(ServerField).(int)),
+		Time:        api.String(d.Get(TimeField).(string)),
+		Type:        api.String(d.Get(TypeField).(string)),
+	}
+}
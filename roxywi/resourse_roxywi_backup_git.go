@@ -2,14 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -20,10 +17,10 @@ const (
 
 func resourceBackupGit() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceBackupGitCreate,
-		ReadWithoutTimeout:   resourceBackupGitRead,
-		UpdateWithoutTimeout: resourceBackupGitUpdate,
-		DeleteWithoutTimeout: resourceBackupGitDelete,
+		CreateContext: resourceBackupGitCreate,
+		ReadContext:   resourceBackupGitRead,
+		UpdateContext: resourceBackupGitUpdate,
+		DeleteContext: resourceBackupGitDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -44,9 +41,10 @@ func resourceBackupGit() *schema.Resource {
 				Description: "Credential ID.",
 			},
 			DescriptionField: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the backup.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Description of the backup.",
+				ValidateFunc: validateStoredText,
 			},
 			BranchField: {
 				Type:        schema.TypeString,
@@ -78,98 +76,46 @@ func resourceBackupGit() *schema.Resource {
 }
 
 func resourceBackupGitCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-
-	backup := map[string]interface{}{
-		CredIDField:      d.Get(CredIDField).(int),
-		DescriptionField: description,
-		BranchField:      d.Get(BranchField).(string),
-		TimeS3Field:      d.Get(TimeS3Field).(string),
-		ServiceIdField:   d.Get(ServiceIdField).(int),
-		ServerField:      d.Get(ServerField).(int),
-		RepoField:        d.Get(RepoField).(string),
-	}
+	client := m.(*Config).API
 
-	resp, err := client.doRequest("POST", "/api/server/backup/git", backup)
+	id, err := client.CreateGitBackup(ctx, expandBackupGit(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	// Добавляем больше логирования для отладки
-	fmt.Printf("Response result: %v\n", result)
-
-	id, ok := result["id"]
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	// Проверка типа id и приведение к строке
-	var idStr string
-	switch v := id.(type) {
-	case string:
-		idStr = v
-	case int:
-		idStr = strconv.Itoa(v)
-	case float64:
-		idStr = strconv.FormatFloat(v, 'f', 0, 64)
-	default:
-		return diag.Errorf("unsupported type for ID: %T", v)
-	}
-
-	d.SetId(idStr)
+	d.SetId(id)
 	return resourceBackupGitRead(ctx, d, m)
 }
 
 func resourceBackupGitRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/server/backup/git/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	backup, err := client.GetGitBackup(ctx, id)
+	if err != nil && backup == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	description := strings.ReplaceAll(result[DescriptionField].(string), "'", "")
-
-	d.Set(CredIDField, result[CredIDField])
-	d.Set(DescriptionField, description)
-	d.Set(BranchField, result[BranchField])
-	d.Set(TimeS3Field, result[TimeS3Field])
-	d.Set(ServerField, result[ServerField])
-	d.Set(ServiceIdField, result[ServiceIdField])
-	d.Set(RepoField, result[RepoField])
+	d.Set(CredIDField, int(backup.CredID))
+	d.Set(DescriptionField, string(backup.Description))
+	d.Set(BranchField, string(backup.Branch))
+	d.Set(TimeS3Field, string(backup.Time))
+	d.Set(ServerField, int(backup.ServerID))
+	d.Set(ServiceIdField, int(backup.ServiceID))
+	d.Set(RepoField, string(backup.Repo))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceBackupGitUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-
-	backup := map[string]interface{}{
-		CredIDField:      d.Get(CredIDField).(int),
-		DescriptionField: description,
-		BranchField:      d.Get(BranchField).(string),
-		TimeS3Field:      d.Get(TimeS3Field).(string),
-		ServiceIdField:   d.Get(ServiceIdField).(int),
-		ServerField:      d.Get(ServerField).(int),
-		RepoField:        d.Get(RepoField).(string),
-	}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/server/backup/git/%s", id), backup)
-	if err != nil {
+	if err := client.UpdateGitBackup(ctx, id, expandBackupGit(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -177,20 +123,25 @@ func resourceBackupGitUpdate(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceBackupGitDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	// Подготовка данных для удаления
-	deleteData := map[string]interface{}{
-		ServerField: d.Get(ServerField).(int),
-		CredIDField: d.Get(CredIDField).(int),
-	}
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/server/backup/git/%s", id), deleteData)
-	if err != nil {
+	if err := client.DeleteGitBackup(ctx, id, d.Get(ServerField).(int), d.Get(CredIDField).(int)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandBackupGit(d *schema.ResourceData) *api.GitBackup {
+	return &api.GitBackup{
+		CredID:      api.Int(d.Get(CredIDField).(int)),
+		Description: api.String(d.Get(DescriptionField).(string)),
+		Branch:      api.String(d.Get(BranchField).(string)),
+		Time:        api.String(d.Get(TimeS3Field).(string)),
+		ServerID:    api.Int(d.Get(ServerField).(int)),
+		ServiceID:   api.Int(d.Get(ServiceIdField).(int)),
+		Repo:        api.String(d.Get(RepoField).(string)),
+	}
+}
@@ -2,14 +2,11 @@ package roxywi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 const (
@@ -22,10 +19,10 @@ const (
 
 func resourceBackupS3() *schema.Resource {
 	return &schema.Resource{
-		CreateWithoutTimeout: resourceBackupS3Create,
-		ReadWithoutTimeout:   resourceBackupS3Read,
-		UpdateWithoutTimeout: resourceBackupS3Update,
-		DeleteWithoutTimeout: resourceBackupS3Delete,
+		CreateContext: resourceBackupS3Create,
+		ReadContext:   resourceBackupS3Read,
+		UpdateContext: resourceBackupS3Update,
+		DeleteContext: resourceBackupS3Delete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -46,9 +43,10 @@ func resourceBackupS3() *schema.Resource {
 				Description: "S3 server endpoint.",
 			},
 			DescriptionField: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the backup.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Description of the backup.",
+				ValidateFunc: validateStoredText,
 			},
 			AccessKey: {
 				Type:        schema.TypeString,
@@ -80,108 +78,46 @@ func resourceBackupS3() *schema.Resource {
 }
 
 func resourceBackupS3Create(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
-
-	// Получение значений из схемы
-	s3Server := d.Get(S3Server).(string)
-	accessKey := d.Get(AccessKey).(string)
-	secretKey := d.Get(SecretKey).(string)
-	bucket := d.Get(Bucket).(string)
-	serverID := d.Get(ServerField).(int)
-	backupTime := d.Get(TimeS3Field).(string)
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-
-	// Создаём резервные копии S3 на основе данных схемы
-	backup := map[string]interface{}{
-		S3Server:         s3Server,
-		AccessKey:        accessKey,
-		SecretKey:        secretKey,
-		Bucket:           bucket,
-		ServerField:      serverID,
-		TimeS3Field:      backupTime,
-		DescriptionField: description,
-	}
+	client := m.(*Config).API
 
-	resp, err := client.doRequest("POST", "/api/server/backup/s3", backup)
+	id, err := client.CreateS3Backup(ctx, expandBackupS3(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return diag.FromErr(err)
-	}
-
-	// Добавляем больше логирования для отладки
-	fmt.Printf("Response result: %v\n", result)
-
-	id, ok := result["id"]
-	if !ok {
-		return diag.Errorf("unable to find ID in response: %v", result)
-	}
-
-	// Проверка типа id и приведение к строке
-	var idStr string
-	switch v := id.(type) {
-	case string:
-		idStr = v
-	case int:
-		idStr = strconv.Itoa(v)
-	case float64:
-		idStr = strconv.FormatFloat(v, 'f', 0, 64)
-	default:
-		return diag.Errorf("unsupported type for ID: %T", v)
-	}
-
-	d.SetId(idStr)
+	d.SetId(id)
 	return resourceBackupS3Read(ctx, d, m)
 }
 
 func resourceBackupS3Read(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	resp, err := client.doRequest("GET", fmt.Sprintf("/api/server/backup/s3/%s", id), nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	backup, err := client.GetS3Backup(ctx, id)
+	if err != nil && backup == nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 
-	description := strings.ReplaceAll(result[DescriptionField].(string), "'", "")
-
-	d.Set(S3Server, result[S3Server])
-	d.Set(DescriptionField, description)
-	d.Set(AccessKey, result[AccessKey])
-	d.Set(SecretKey, result[SecretKey])
-	d.Set(Bucket, result[Bucket])
-	d.Set(ServerField, result[ServerField])
-	d.Set(TimeField, result[TimeField])
-	d.Set(DescriptionField, result[DescriptionField])
+	d.Set(S3Server, string(backup.S3Server))
+	d.Set(DescriptionField, string(backup.Description))
+	d.Set(AccessKey, string(backup.AccessKey))
+	d.Set(SecretKey, string(backup.SecretKey))
+	d.Set(Bucket, string(backup.Bucket))
+	d.Set(ServerField, int(backup.ServerID))
+	d.Set(TimeS3Field, string(backup.Time))
 
-	return nil
+	return apiDiagnostics(err)
 }
 
 func resourceBackupS3Update(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
-	description := strings.ReplaceAll(d.Get(DescriptionField).(string), "'", "")
-
-	backup := map[string]interface{}{
-		S3Server:         d.Get(S3Server).(string),
-		DescriptionField: description,
-		AccessKey:        d.Get(AccessKey).(string),
-		SecretKey:        d.Get(SecretKey).(string),
-		Bucket:           d.Get(Bucket).(string),
-		ServerField:      d.Get(ServerField).(int),
-		TimeField:        d.Get(TimeField).(string),
-	}
 
-	_, err := client.doRequest("PUT", fmt.Sprintf("/api/server/backup/s3/%s", id), backup)
-	if err != nil {
+	if err := client.UpdateS3Backup(ctx, id, expandBackupS3(d)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -189,20 +125,25 @@ func resourceBackupS3Update(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func resourceBackupS3Delete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Config).Client
+	client := m.(*Config).API
 	id := d.Id()
 
-	// Подготовка данных для удаления
-	deleteData := map[string]interface{}{
-		Bucket:      d.Get(Bucket).(int),
-		ServerField: d.Get(ServerField).(int),
-	}
-
-	_, err := client.doRequest("DELETE", fmt.Sprintf("/api/server/backup/s3/%s", id), deleteData)
-	if err != nil {
+	if err := client.DeleteS3Backup(ctx, id, d.Get(ServerField).(int), d.Get(Bucket).(string)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	return nil
 }
+
+func expandBackupS3(d *schema.ResourceData) *api.S3Backup {
+	return &api.S3Backup{
+		S3Server:    api.String(d.Get(S3Server).(string)),
+		Description: api.String(d.Get(DescriptionField).(string)),
+		AccessKey:   api.String(d.Get(AccessKey).(string)),
+		SecretKey:   api.String(d.Get(SecretKey).(string)),
+		Bucket:      api.String(d.Get(Bucket).(string)),
+		ServerID:    api.Int(d.Get(ServerField).(int)),
+		Time:        api.String(d.Get(TimeS3Field).(string)),
+	}
+}
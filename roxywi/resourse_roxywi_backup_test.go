@@ -0,0 +1,90 @@
+package roxywi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+func testBackupConfig(srv *roxywitest.Server, description string) string {
+	return testProviderConfig(srv) + fmt.Sprintf(`
+resource "roxywi_backup_fs" "test" {
+  cred_id     = 1
+  description = %[1]q
+  rpath       = "/var/backup"
+  rserver     = "10.0.0.20"
+  server_id   = 1
+  time        = "daily"
+  type        = "synchronization"
+}
+
+resource "roxywi_backup_git" "test" {
+  cred_id     = 1
+  description = %[1]q
+  repo        = "git@example.com:ops/haproxy-configs"
+  branch      = "main"
+  server_id   = 1
+  time        = "daily"
+  service_id  = 1
+}
+
+resource "roxywi_backup_s3" "test" {
+  access_key  = "access"
+  secret_key  = "secret"
+  bucket      = "configs"
+  description = %[1]q
+  server_id   = 1
+  s3_server   = "https://s3.example.com"
+  time        = "daily"
+}
+`, description)
+}
+
+func TestResourceBackups(t *testing.T) {
+	srv := newTestServer(t)
+
+	backupTypes := []string{"fs", "git", "s3"}
+	checkDestroy := func(s *terraform.State) error {
+		for _, backupType := range backupTypes {
+			check := testCheckDestroyed(srv, "roxywi_backup_"+backupType, idPath("api/server/backup/"+backupType))
+			if err := check(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var checks, updateChecks []resource.TestCheckFunc
+	var imports []resource.TestStep
+	for _, backupType := range backupTypes {
+		address := "roxywi_backup_" + backupType + ".test"
+		checks = append(checks,
+			testCheckExists(srv, address, idPath("api/server/backup/"+backupType)),
+			resource.TestCheckResourceAttr(address, "description", "nightly"),
+		)
+		updateChecks = append(updateChecks, resource.TestCheckResourceAttr(address, "description", "nightly, kept a week"))
+		imports = append(imports, resource.TestStep{
+			ResourceName:      address,
+			ImportState:       true,
+			ImportStateVerify: true,
+		})
+	}
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      checkDestroy,
+		Steps: append([]resource.TestStep{
+			{
+				Config: testBackupConfig(srv, "nightly"),
+				Check:  resource.ComposeTestCheckFunc(checks...),
+			},
+			{
+				Config: testBackupConfig(srv, "nightly, kept a week"),
+				Check:  resource.ComposeTestCheckFunc(updateChecks...),
+			},
+		}, imports...),
+	})
+}
@@ -0,0 +1,115 @@
+package roxywi
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryMinWait = 1 * time.Second
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+// RetryPolicy controls how transient Roxy-WI API failures are retried.
+type RetryPolicy struct {
+	MaxRetries int
+	MinWait    time.Duration
+	MaxWait    time.Duration
+}
+
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		MinWait:    DefaultRetryMinWait,
+		MaxWait:    DefaultRetryMaxWait,
+	}
+}
+
+// shouldRetry reports whether a request that ended with statusCode or err may
+// be sent again. POST and PATCH are not idempotent, so they are only retried
+// when Roxy-WI cannot have processed them: the connection was never
+// established, or the server explicitly refused the request with 429 or 503.
+func (p RetryPolicy) shouldRetry(method string, statusCode int, err error) bool {
+	idempotent := isIdempotent(method)
+
+	if err != nil {
+		if idempotent {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// backoff returns how long to wait before retry number attempt (starting at
+// 0). A Retry-After header takes precedence over the exponential schedule;
+// both are capped at MaxWait.
+func (p RetryPolicy) backoff(attempt int, header http.Header) time.Duration {
+	if wait, ok := retryAfter(header); ok {
+		if wait > p.MaxWait {
+			return p.MaxWait
+		}
+		return wait
+	}
+
+	wait := p.MinWait
+	for i := 0; i < attempt && wait < p.MaxWait; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxWait {
+		wait = p.MaxWait
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	// Jitter into [wait/2, wait] so that parallel resources do not retry in
+	// lockstep. The upper end is inclusive, which keeps MaxWait reachable but
+	// never exceeded.
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,328 @@
+package roxywi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := defaultRetryPolicy()
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	cases := []struct {
+		method     string
+		statusCode int
+		err        error
+		want       bool
+	}{
+		{http.MethodGet, http.StatusOK, nil, false},
+		{http.MethodGet, http.StatusNotFound, nil, false},
+		{http.MethodGet, http.StatusInternalServerError, nil, false},
+		{http.MethodGet, http.StatusBadGateway, nil, true},
+		{http.MethodGet, http.StatusServiceUnavailable, nil, true},
+		{http.MethodGet, http.StatusGatewayTimeout, nil, true},
+		{http.MethodGet, http.StatusTooManyRequests, nil, true},
+		{http.MethodGet, 0, readErr, true},
+		{http.MethodGet, 0, io.ErrUnexpectedEOF, true},
+		{http.MethodPut, http.StatusBadGateway, nil, true},
+		{http.MethodDelete, 0, readErr, true},
+		{http.MethodPost, http.StatusTooManyRequests, nil, true},
+		{http.MethodPost, http.StatusServiceUnavailable, nil, true},
+		{http.MethodPost, http.StatusBadGateway, nil, false},
+		{http.MethodPost, http.StatusGatewayTimeout, nil, false},
+		{http.MethodPost, 0, dialErr, true},
+		{http.MethodPost, 0, fmt.Errorf("Post: %w", dialErr), true},
+		{http.MethodPost, 0, readErr, false},
+		{http.MethodPost, 0, io.ErrUnexpectedEOF, false},
+		{http.MethodPatch, 0, readErr, false},
+		{http.MethodPatch, http.StatusServiceUnavailable, nil, true},
+	}
+
+	for _, tc := range cases {
+		got := policy.shouldRetry(tc.method, tc.statusCode, tc.err)
+		if got != tc.want {
+			t.Errorf("shouldRetry(%s, %d, %v) = %v, want %v", tc.method, tc.statusCode, tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, MinWait: 100 * time.Millisecond, MaxWait: time.Second}
+
+	cases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{10, time.Second},
+	}
+
+	for _, tc := range cases {
+		for i := 0; i < 50; i++ {
+			wait := policy.backoff(tc.attempt, http.Header{})
+			if wait < tc.max/2 || wait > tc.max {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", tc.attempt, wait, tc.max/2, tc.max)
+			}
+		}
+	}
+
+	if wait := (RetryPolicy{}).backoff(3, http.Header{}); wait != 0 {
+		t.Errorf("backoff without MinWait = %s, want 0", wait)
+	}
+}
+
+func TestRetryPolicyBackoffRetryAfter(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinWait: 100 * time.Millisecond, MaxWait: 10 * time.Second}
+
+	header := func(value string) http.Header {
+		h := http.Header{}
+		h.Set("Retry-After", value)
+		return h
+	}
+
+	if wait := policy.backoff(0, header("3")); wait != 3*time.Second {
+		t.Errorf("Retry-After: 3 gave %s, want 3s", wait)
+	}
+	if wait := policy.backoff(0, header("0")); wait != 0 {
+		t.Errorf("Retry-After: 0 gave %s, want 0", wait)
+	}
+	if wait := policy.backoff(0, header("120")); wait != policy.MaxWait {
+		t.Errorf("Retry-After: 120 gave %s, want it capped at %s", wait, policy.MaxWait)
+	}
+
+	date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if wait := policy.backoff(0, header(date)); wait <= 3*time.Second || wait > 5*time.Second {
+		t.Errorf("Retry-After: %s gave %s, want about 5s", date, wait)
+	}
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	if wait := policy.backoff(0, header(past)); wait != 0 {
+		t.Errorf("Retry-After in the past gave %s, want 0", wait)
+	}
+
+	// An unparsable value falls back to the exponential schedule.
+	if wait := policy.backoff(0, header("soon")); wait < 50*time.Millisecond || wait > 100*time.Millisecond {
+		t.Errorf("Retry-After: soon gave %s, want the exponential backoff", wait)
+	}
+}
+
+// newRetryTestClient returns a client for srv that authenticates with a
+// static API token and retries quickly.
+func newRetryTestClient(t *testing.T, srv *httptest.Server, maxRetries int) *Client {
+	t.Helper()
+
+	client, err := NewClient(context.Background(), srv.URL, "", "", "test",
+		WithAPIToken("token"),
+		WithRetryPolicy(RetryPolicy{MaxRetries: maxRetries, MinWait: time.Millisecond, MaxWait: 10 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"status": "Ok"}`)
+	}))
+	defer srv.Close()
+
+	client := newRetryTestClient(t, srv, 3)
+	resp, err := client.doRequest(context.Background(), http.MethodGet, "api/server/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp) != `{"status": "Ok"}` {
+		t.Errorf("unexpected response %q", resp)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("got %d requests, want 3", atomic.LoadInt32(&calls))
+	}
+}
+
+func TestClientHonoursRetryAfter(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	var first time.Time
+	var elapsed time.Duration
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if atomic.AddInt32(&calls, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		elapsed = time.Since(first)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 1}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(context.Background(), srv.URL, "", "", "test",
+		WithAPIToken("token"),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1, MinWait: time.Millisecond, MaxWait: 5 * time.Second}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 429 means the request was refused, so even a POST is sent again.
+	if _, err := client.doRequest(context.Background(), http.MethodPost, "api/server", map[string]string{"hostname": "test"}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("got %d requests, want 2", atomic.LoadInt32(&calls))
+	}
+	if elapsed < 900*time.Millisecond {
+		t.Errorf("retried after %s, want the 1s from Retry-After", elapsed)
+	}
+}
+
+// hangUpAfterBody reads the request body and then drops the connection
+// without answering.
+func hangUpAfterBody(calls *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		io.Copy(io.Discard, r.Body)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			panic(err)
+		}
+		conn.Close()
+	}
+}
+
+func TestClientDoesNotRetryPostAfterBodyWasSent(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(hangUpAfterBody(&calls))
+	defer srv.Close()
+
+	client := newRetryTestClient(t, srv, 3)
+	_, err := client.doRequest(context.Background(), http.MethodPost, "api/server", map[string]string{"hostname": "test"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("POST was sent %d times, want 1", atomic.LoadInt32(&calls))
+	}
+}
+
+func TestClientRetriesIdempotentRequestAfterBodyWasSent(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(hangUpAfterBody(&calls))
+	defer srv.Close()
+
+	client := newRetryTestClient(t, srv, 2)
+	_, err := client.doRequest(context.Background(), http.MethodPut, "api/server/1", map[string]string{"hostname": "test"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("PUT was sent %d times, want 3", atomic.LoadInt32(&calls))
+	}
+}
+
+func TestClientRetriesPostWhenConnectionWasRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client, err := NewClient(context.Background(), "http://"+addr, "", "", "test",
+		WithAPIToken("token"),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: 10 * time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.doRequest(context.Background(), http.MethodPost, "api/server", map[string]string{"hostname": "test"})
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		t.Fatalf("expected a dial error, got %v", err)
+	}
+}
+
+func TestClientReturnsLastErrorWhenRetriesAreExhausted(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, `{"status": "failed", "error": "attempt %d"}`, n)
+	}))
+	defer srv.Close()
+
+	client := newRetryTestClient(t, srv, 2)
+	_, err := client.doRequest(context.Background(), http.MethodGet, "api/server/1", nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("got %d requests, want 3", atomic.LoadInt32(&calls))
+	}
+
+	var httpErr *httpError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected an httpError, got %T: %v", err, err)
+	}
+	if httpErr.StatusCode != http.StatusServiceUnavailable || httpErr.Message != "attempt 3" {
+		t.Errorf("got %v, want the 503 of the last attempt", err)
+	}
+	if !strings.Contains(err.Error(), "GET api/server/1") {
+		t.Errorf("error %q does not name the request", err)
+	}
+}
+
+func TestClientStopsRetryingWhenContextIsDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(context.Background(), srv.URL, "", "", "test",
+		WithAPIToken("token"),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, MinWait: time.Millisecond, MaxWait: 10 * time.Second}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.doRequest(ctx, http.MethodGet, "api/server/1", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("gave up after %s, want right after the deadline", time.Since(start))
+	}
+}
@@ -0,0 +1,102 @@
+package roxywitest
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Fault describes a failure injected into matching requests.
+type Fault struct {
+	// Method restricts the fault to one HTTP method. Empty matches any method.
+	Method string
+	// Path is a regular expression matched against the request path without
+	// the leading slash, e.g. `^api/server/\d+$`.
+	Path string
+	// StatusCode is returned instead of the normal response. Zero lets the
+	// request through after Delay.
+	StatusCode int
+	// Header is added to the response when StatusCode is set.
+	Header http.Header
+	// Delay is waited before responding, or until the client gives up.
+	Delay time.Duration
+	// Times limits how many requests the fault applies to. Zero means every
+	// matching request.
+	Times int
+
+	pattern *regexp.Regexp
+	hits    int
+}
+
+// NotFound makes requests matching method and path fail with 404.
+func NotFound(method, path string) Fault {
+	return Fault{Method: method, Path: path, StatusCode: http.StatusNotFound}
+}
+
+// ServerError makes the next times requests matching method and path fail
+// with 500.
+func ServerError(method, path string, times int) Fault {
+	return Fault{Method: method, Path: path, StatusCode: http.StatusInternalServerError, Times: times}
+}
+
+// TooManyRequests makes the next times requests matching method and path
+// fail with 429 and a Retry-After header asking the client to wait
+// retryAfter, rounded down to whole seconds.
+func TooManyRequests(method, path string, retryAfter time.Duration, times int) Fault {
+	return Fault{
+		Method:     method,
+		Path:       path,
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{strconv.Itoa(int(retryAfter / time.Second))}},
+		Times:      times,
+	}
+}
+
+// Unauthorized makes the next times requests matching method and path fail
+// with 401, as Roxy-WI answers once a token has expired or been revoked.
+func Unauthorized(method, path string, times int) Fault {
+	return Fault{Method: method, Path: path, StatusCode: http.StatusUnauthorized, Times: times}
+}
+
+// Slow delays responses to requests matching method and path.
+func Slow(method, path string, delay time.Duration) Fault {
+	return Fault{Method: method, Path: path, Delay: delay}
+}
+
+// InjectFault adds f to the server. Faults are checked in the order they
+// were added and the first match wins. It panics if f.Path is not a valid
+// regular expression.
+func (s *Server) InjectFault(f Fault) {
+	f.pattern = regexp.MustCompile(f.Path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// matchFault returns the fault to apply to a request. It must be called with
+// s.mu held.
+func (s *Server) matchFault(method, path string) *Fault {
+	for _, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if !f.pattern.MatchString(path) {
+			continue
+		}
+		if f.Times > 0 && f.hits >= f.Times {
+			continue
+		}
+		f.hits++
+		return f
+	}
+	return nil
+}
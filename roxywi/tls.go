@@ -0,0 +1,106 @@
+package roxywi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TLSSettings describes how the client verifies Roxy-WI and authenticates to
+// it on the TLS layer.
+type TLSSettings struct {
+	CACertFile         string
+	CACertPEM          string
+	ClientCert         string
+	ClientKey          string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// WithTLSSettings configures the client's transport from settings.
+func WithTLSSettings(settings TLSSettings) ClientOption {
+	return func(c *Client) {
+		transport, err := settings.Transport()
+		if err != nil {
+			c.optErr = err
+			return
+		}
+		c.httpClient.Transport = transport
+	}
+}
+
+// Transport returns a clone of http.DefaultTransport configured with the
+// settings, for callers that wrap the transport before passing it to
+// WithTransport.
+func (s TLSSettings) Transport() (*http.Transport, error) {
+	tlsConfig, err := s.build()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+func (s TLSSettings) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         s.ServerName,
+		InsecureSkipVerify: s.InsecureSkipVerify,
+	}
+
+	if s.CACertFile != "" || s.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		caPEM := []byte(s.CACertPEM)
+		if s.CACertFile != "" {
+			caPEM, err = os.ReadFile(s.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read CA certificate file: %w", err)
+			}
+		}
+
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid PEM certificates found in the CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if s.ClientCert != "" || s.ClientKey != "" {
+		if s.ClientCert == "" || s.ClientKey == "" {
+			return nil, fmt.Errorf("both client certificate and client key must be set for mutual TLS")
+		}
+
+		certPEM, err := pemOrFile(s.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client certificate: %w", err)
+		}
+		keyPEM, err := pemOrFile(s.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client key: %w", err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// pemOrFile returns value itself if it is PEM-encoded, otherwise the contents
+// of the file it points to.
+func pemOrFile(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
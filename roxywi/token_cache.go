@@ -0,0 +1,134 @@
+package roxywi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// tokenCache stores Roxy-WI session tokens on disk so that provider processes
+// started one after another can reuse a login instead of calling /api/login
+// every time. Entries are keyed by base URL and the login or credential
+// process; only tokens with a known expiry are cached. The directory and its
+// files must belong to the current user and be inaccessible to anyone else.
+type tokenCache struct {
+	dir string
+}
+
+type tokenCacheEntry struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newTokenCache(dir string) *tokenCache {
+	return &tokenCache{dir: dir}
+}
+
+// path returns the cache file for baseURL and key. The key is hashed so
+// that neither appears in the file name.
+func (tc *tokenCache) path(baseURL, key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimRight(baseURL, "/") + "\x00" + key))
+	return filepath.Join(tc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load returns the cached entry for baseURL and key if there is one whose
+// token does not expire within tokenExpirySkew. A cache file that is not a
+// regular file, that other users can access or that another user owns is
+// refused with an error, as its token may have been read or planted.
+func (tc *tokenCache) Load(baseURL, key string) (tokenCacheEntry, bool, error) {
+	name := tc.path(baseURL, key)
+	info, err := os.Lstat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokenCacheEntry{}, false, nil
+	}
+	if err != nil {
+		return tokenCacheEntry{}, false, fmt.Errorf("unable to read token cache: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return tokenCacheEntry{}, false, fmt.Errorf("token cache %s is not a regular file", name)
+	}
+	if !privateMode(info.Mode()) {
+		return tokenCacheEntry{}, false, fmt.Errorf("token cache %s is accessible by other users (mode %s)", name, info.Mode().Perm())
+	}
+	if err := checkTokenCacheOwner(info); err != nil {
+		return tokenCacheEntry{}, false, fmt.Errorf("token cache %s %w", name, err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return tokenCacheEntry{}, false, fmt.Errorf("unable to read token cache: %w", err)
+	}
+
+	var entry tokenCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Token == "" {
+		return tokenCacheEntry{}, false, nil
+	}
+	if entry.ExpiresAt.IsZero() || time.Now().Add(tokenExpirySkew).After(entry.ExpiresAt) {
+		return tokenCacheEntry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// Store writes token to the cache, readable only by the current user. Tokens
+// without an expiry are not stored.
+func (tc *tokenCache) Store(baseURL, key, token string, expiresAt time.Time) error {
+	if expiresAt.IsZero() {
+		return nil
+	}
+
+	if err := os.MkdirAll(tc.dir, 0o700); err != nil {
+		return fmt.Errorf("unable to create token cache directory: %w", err)
+	}
+
+	// MkdirAll leaves the mode of an existing directory as it is.
+	info, err := os.Stat(tc.dir)
+	if err != nil {
+		return fmt.Errorf("unable to create token cache directory: %w", err)
+	}
+	if err := checkTokenCacheOwner(info); err != nil {
+		return fmt.Errorf("token cache directory %s %w", tc.dir, err)
+	}
+	if !privateMode(info.Mode()) {
+		if err := os.Chmod(tc.dir, 0o700); err != nil {
+			return fmt.Errorf("unable to restrict token cache directory: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(tokenCacheEntry{Token: token, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+
+	// CreateTemp creates the file with mode 0600.
+	name := tc.path(baseURL, key)
+	tmp, err := os.CreateTemp(tc.dir, filepath.Base(name)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write token cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write token cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write token cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// privateMode reports whether mode grants no access to the group and others.
+// Windows controls access with ACLs rather than mode bits, so every mode is
+// private there.
+func privateMode(mode fs.FileMode) bool {
+	return runtime.GOOS == "windows" || mode.Perm()&0o077 == 0
+}
@@ -0,0 +1,206 @@
+package roxywi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+const testCacheURL = "https://roxy.example.com/"
+
+func TestTokenCacheStoreAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	tc := newTokenCache(dir)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	if _, ok, err := tc.Load(testCacheURL, "admin"); ok || err != nil {
+		t.Fatalf("Load() on an empty cache = %v, %v, want a miss", ok, err)
+	}
+	if err := tc.Store(testCacheURL, "admin", "token", expiry); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok, err := tc.Load(testCacheURL, "admin")
+	if err != nil || !ok || entry.Token != "token" || !entry.ExpiresAt.Equal(expiry) {
+		t.Fatalf("Load() = %+v, %v, %v, want the stored token", entry, ok, err)
+	}
+	if _, ok, _ := tc.Load(testCacheURL, "other"); ok {
+		t.Error("Load() returned the token of another login")
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	for name, want := range map[string]os.FileMode{dir: 0o700, tc.path(testCacheURL, "admin"): 0o600} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s has mode %s, want %s", name, info.Mode().Perm(), want)
+		}
+	}
+}
+
+func TestTokenCacheSkipsExpiringTokens(t *testing.T) {
+	tc := newTokenCache(t.TempDir())
+
+	if err := tc.Store(testCacheURL, "admin", "token", time.Now().Add(tokenExpirySkew/2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := tc.Load(testCacheURL, "admin"); ok || err != nil {
+		t.Errorf("Load() = %v, %v, want a token about to expire to be ignored", ok, err)
+	}
+}
+
+func TestTokenCacheRestrictsExistingDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mode bits are not used for access control on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := newTokenCache(dir).Store(testCacheURL, "admin", "token", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("directory mode = %s, want 0700", info.Mode().Perm())
+	}
+}
+
+func TestTokenCacheRefusesUnsafeFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mode bits and ownership are not checked on Windows")
+	}
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, name string)
+		wantErr string
+	}{
+		{
+			name: "readable by others",
+			prepare: func(t *testing.T, name string) {
+				if err := os.Chmod(name, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "is accessible by other users (mode -rw-r--r--)",
+		},
+		{
+			name: "writable by the group",
+			prepare: func(t *testing.T, name string) {
+				if err := os.Chmod(name, 0o620); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "is accessible by other users (mode -rw--w----)",
+		},
+		{
+			name: "symbolic link",
+			prepare: func(t *testing.T, name string) {
+				target := name + ".target"
+				if err := os.Rename(name, target); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(target, name); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "is not a regular file",
+		},
+		{
+			name: "owned by another user",
+			prepare: func(t *testing.T, name string) {
+				if os.Getuid() != 0 {
+					t.Skip("changing the owner of a file requires root")
+				}
+				if err := os.Chown(name, 65534, 65534); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "is owned by uid 65534 instead of the current user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := newTokenCache(t.TempDir())
+			if err := tc.Store(testCacheURL, "admin", "token", time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			tt.prepare(t, tc.path(testCacheURL, "admin"))
+
+			_, ok, err := tc.Load(testCacheURL, "admin")
+			if ok || err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() = %v, %v, want an error containing %q", ok, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenCacheRefusesForeignDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() != 0 {
+		t.Skip("changing the owner of a directory requires root")
+	}
+
+	dir := t.TempDir()
+	if err := os.Chown(dir, 65534, 65534); err != nil {
+		t.Fatal(err)
+	}
+	err := newTokenCache(dir).Store(testCacheURL, "admin", "token", time.Now().Add(time.Hour))
+	if err == nil || !strings.Contains(err.Error(), "is owned by uid 65534") {
+		t.Errorf("Store() error = %v, want the directory to be refused", err)
+	}
+}
+
+// TestTokenCacheWithCredentialProcess checks that a second client with the
+// same credential process reuses the cached token without running the
+// process or logging in.
+func TestTokenCacheWithCredentialProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the credential process below is a POSIX shell command")
+	}
+
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	command := `echo run >> ` + runs + `; echo '{"login": "` + roxywitest.DefaultLogin + `", "password": "` + roxywitest.DefaultPassword + `"}'`
+
+	for i := 0; i < 2; i++ {
+		client, err := NewClient(context.Background(), srv.URL+"/", "", "", "test",
+			WithCredentialProcess(command),
+			WithTokenCache(filepath.Join(dir, "cache")),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.doRequest(context.Background(), "GET", "/api/groups", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "run"); n != 1 {
+		t.Errorf("the credential process ran %d times, want once", n)
+	}
+	if n := len(requestsTo(srv, "POST", `^api/login$`)); n != 1 {
+		t.Errorf("got %d logins, want the second client to reuse the cached token", n)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package roxywi
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// checkTokenCacheOwner returns an error unless the token cache file or
+// directory described by info belongs to the current user.
+func checkTokenCacheOwner(info fs.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Getuid(); int(stat.Uid) != uid {
+		return fmt.Errorf("is owned by uid %d instead of the current user (uid %d)", stat.Uid, uid)
+	}
+	return nil
+}
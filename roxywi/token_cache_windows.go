@@ -0,0 +1,9 @@
+package roxywi
+
+import "io/fs"
+
+// checkTokenCacheOwner does nothing on Windows, where the token cache relies
+// on the ACLs of the user profile directory instead.
+func checkTokenCacheOwner(info fs.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,137 @@
+package roxywi
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by the provider.
+const tracerName = "terraform-provider-roxywi"
+
+var (
+	tracingOnce     sync.Once
+	tracingErr      error
+	tracingProvider *sdktrace.TracerProvider
+)
+
+// tracingEnabled reports whether an OTLP endpoint is configured through the
+// standard OpenTelemetry environment variables.
+func tracingEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// setupTracing installs an OTLP/HTTP exporter as the global tracer provider
+// when tracing is enabled. The exporter, sampler and resource are configured
+// by the OTEL_* environment variables. It only does work on its first call;
+// the provider is configured again for every alias.
+func setupTracing(ctx context.Context) error {
+	tracingOnce.Do(func() {
+		if !tracingEnabled() {
+			return
+		}
+
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			tracingErr = err
+			return
+		}
+
+		res, err := resource.New(ctx,
+			resource.WithAttributes(attribute.String("service.name", tracerName)),
+			resource.WithFromEnv(),
+			resource.WithTelemetrySDK(),
+		)
+		if err != nil {
+			tracingErr = err
+			return
+		}
+
+		tracingProvider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+		)
+		otel.SetTracerProvider(tracingProvider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	})
+	return tracingErr
+}
+
+// ShutdownTracing flushes spans that have not been exported yet. It is called
+// when the plugin process exits.
+func ShutdownTracing(ctx context.Context) error {
+	if tracingProvider == nil {
+		return nil
+	}
+	return tracingProvider.Shutdown(ctx)
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// operationFunc is the signature shared by the CRUD functions of resources and
+// data sources.
+type operationFunc func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+
+// traceOperation runs fn in a span named after the Terraform type and
+// operation, e.g. "roxywi_server.create". Roxy-WI calls made by fn become
+// its children.
+func traceOperation(typeName, operation string, fn operationFunc) operationFunc {
+	if fn == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		ctx, span := tracer().Start(ctx, typeName+"."+operation, trace.WithAttributes(
+			attribute.String("terraform.type", typeName),
+			attribute.String("terraform.operation", operation),
+			attribute.String("terraform.id", d.Id()),
+		))
+		defer span.End()
+
+		diags := fn(ctx, d, m)
+		if diags.HasError() {
+			for _, diagnostic := range diags {
+				if diagnostic.Severity == diag.Error {
+					span.SetStatus(codes.Error, diagnostic.Summary)
+					break
+				}
+			}
+		}
+		if id := d.Id(); id != "" {
+			span.SetAttributes(attribute.String("terraform.id", id))
+		}
+		return diags
+	}
+}
+
+// traceResources wraps the CRUD functions of every resource and data source
+// with traceOperation.
+func traceResources(resources map[string]*schema.Resource, dataSource bool) {
+	for name, r := range resources {
+		if dataSource {
+			r.ReadContext = schema.ReadContextFunc(traceOperation(name, "read_data", operationFunc(r.ReadContext)))
+			continue
+		}
+		r.CreateContext = schema.CreateContextFunc(traceOperation(name, "create", operationFunc(r.CreateContext)))
+		r.ReadContext = schema.ReadContextFunc(traceOperation(name, "read", operationFunc(r.ReadContext)))
+		r.UpdateContext = schema.UpdateContextFunc(traceOperation(name, "update", operationFunc(r.UpdateContext)))
+		r.DeleteContext = schema.DeleteContextFunc(traceOperation(name, "delete", operationFunc(r.DeleteContext)))
+	}
+}
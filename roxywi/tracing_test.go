@@ -0,0 +1,122 @@
+package roxywi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"terraform-provider-roxywi/roxywi/roxywitest"
+)
+
+// recordSpans installs a tracer provider that keeps every span in memory for
+// the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// spanAttribute returns the value of the attribute key of span.
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracingSpans(t *testing.T) {
+	recorder := recordSpans(t)
+	srv := newTestServer(t)
+	srv.InjectFault(roxywitest.Fault{
+		Method:     http.MethodPost,
+		Path:       `^api/group$`,
+		StatusCode: http.StatusServiceUnavailable,
+		Times:      1,
+	})
+
+	unitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + testGroupConfig,
+			},
+		},
+	})
+
+	spans := recorder.Ended()
+	byID := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byID[span.SpanContext().SpanID().String()] = span
+	}
+
+	var found bool
+	for _, span := range spans {
+		if span.Name() != "POST /api/group" {
+			continue
+		}
+		found = true
+
+		parent, ok := byID[span.Parent().SpanID().String()]
+		if !ok || parent.Name() != "roxywi_group.create" {
+			t.Errorf("parent of %q = %v, want the roxywi_group.create span", span.Name(), parent)
+		}
+		if parent != nil && parent.SpanContext().TraceID() != span.SpanContext().TraceID() {
+			t.Errorf("%q is not in the trace of its CRUD span", span.Name())
+		}
+
+		if v, ok := spanAttribute(span, "http.response.status_code"); !ok || v.AsInt64() != http.StatusCreated && v.AsInt64() != http.StatusOK {
+			t.Errorf("%q http.response.status_code = %v, want the status of the successful retry", span.Name(), v.Emit())
+		}
+		if v, ok := spanAttribute(span, "roxywi.retry_count"); !ok || v.AsInt64() != 1 {
+			t.Errorf("%q roxywi.retry_count = %v, want 1", span.Name(), v.Emit())
+		}
+		if n := len(span.Events()); n != 1 || span.Events()[0].Name != "retry" {
+			t.Errorf("%q has events %v, want one retry event", span.Name(), span.Events())
+		}
+	}
+	if !found {
+		t.Fatal("no span for the create request")
+	}
+
+	var reads int
+	for _, span := range spans {
+		if !strings.HasPrefix(span.Name(), "GET /api/group/") {
+			continue
+		}
+		reads++
+
+		parent := byID[span.Parent().SpanID().String()]
+		if parent == nil {
+			t.Errorf("%q has no parent span", span.Name())
+			continue
+		}
+		switch parent.Name() {
+		case "roxywi_group.create", "roxywi_group.read":
+		default:
+			t.Errorf("parent of %q = %q, want a roxywi_group CRUD span", span.Name(), parent.Name())
+		}
+		if v, ok := spanAttribute(span, "roxywi.retry_count"); !ok || v.AsInt64() != 0 {
+			t.Errorf("%q roxywi.retry_count = %v, want 0", span.Name(), v.Emit())
+		}
+	}
+	if reads == 0 {
+		t.Error("no span for the read request")
+	}
+}
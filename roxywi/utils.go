@@ -3,18 +3,9 @@ package roxywi
 import (
 	"fmt"
 	"strings"
-)
-
-func boolToInt(b bool) int {
-	if b {
-		return 1
-	}
-	return 0
-}
 
-func intToBool(i float64) bool {
-	return i == 1
-}
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
 
 func resourceParseId(fullId string, delimiter string) (string, string, error) {
 	parts := strings.Split(fullId, delimiter)
@@ -23,3 +14,15 @@ func resourceParseId(fullId string, delimiter string) (string, string, error) {
 	}
 	return parts[0], parts[1], nil
 }
+
+// storedTextStrippedChars are the characters Roxy-WI removes from request
+// fields declared as EscapedString (app/modules/roxywi/class_models.py in
+// github.com/roxy-wi/roxy-wi), which strips every match of [&;|$`]. Those are
+// the names, usernames, hostnames and descriptions of servers, SSH
+// credentials, HA clusters, UDP listeners and backups.
+const storedTextStrippedChars = "&;|$`"
+
+// validateStoredText rejects values Roxy-WI would store altered, which would
+// otherwise show up as a permanent diff. Use it only on fields Roxy-WI
+// declares as EscapedString; other fields are stored as sent.
+var validateStoredText = validation.StringDoesNotContainAny(storedTextStrippedChars)
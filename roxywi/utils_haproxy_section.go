@@ -2,34 +2,13 @@ package roxywi
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"strconv"
 	"strings"
-)
 
-func parseServersList(configList []interface{}) []map[string]interface{} {
-	var configs []map[string]interface{}
-	for _, config := range configList {
-		configDetails := config.(map[string]interface{})
-		configs = append(configs, map[string]interface{}{
-			EthField:    configDetails[EthField].(string),
-			IDField:     intFromInterface(configDetails[IDField]),
-			MasterField: boolToInt(configDetails[MasterField].(bool)),
-		})
-	}
-	return configs
-}
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
+)
 
-func parseServersResult(config []map[string]interface{}) []interface{} {
-	var configList []interface{}
-	for _, c := range config {
-		configList = append(configList, map[string]interface{}{
-			EthField:    c[EthField].(string),
-			IDField:     intFromInterface(c[IDField]),
-			MasterField: c[MasterField],
-		})
-	}
-	return configList
-}
 func parsePeersConfigList(configList []interface{}) []map[string]interface{} {
 	var configs []map[string]interface{}
 	for _, config := range configList {
@@ -43,13 +22,14 @@ func parsePeersConfigList(configList []interface{}) []map[string]interface{} {
 	return configs
 }
 
-func parsePeersConfigListResult(config []map[string]interface{}) []interface{} {
+func parsePeersConfigListResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			IPField:       c[IPField].(string),
-			PeerNameField: c[PeerNameField].(string),
-			PortField:     intFromInterface(c[PortField]),
+			IPField:       item.String(IPField),
+			PeerNameField: item.String(PeerNameField),
+			PortField:     item.Int(PortField),
 		})
 	}
 	return configList
@@ -124,25 +104,27 @@ func parseHeaderList(configList []interface{}) []map[string]interface{} {
 	return configs
 }
 
-func parseBindsResult(config []map[string]interface{}) []interface{} {
+func parseBindsResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			IPField:   c[IPField].(string),
-			PortField: c[PortField].(float64),
+			IPField:   item.String(IPField),
+			PortField: item.Int(PortField),
 		})
 	}
 	return configList
 }
 
-func parseAclsServerResult(config []map[string]interface{}) []interface{} {
+func parseAclsServerResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			AclIfField:        c[AclIfField].(float64),
-			AclValueField:     c[AclValueField].(string),
-			AclThenField:      c[AclThenField].(float64),
-			AclThenValueField: c[AclThenValueField].(string),
+			AclIfField:        item.Int(AclIfField),
+			AclValueField:     item.String(AclValueField),
+			AclThenField:      item.Int(AclThenField),
+			AclThenValueField: item.String(AclThenValueField),
 		})
 	}
 	if len(configList) == 0 {
@@ -151,14 +133,15 @@ func parseAclsServerResult(config []map[string]interface{}) []interface{} {
 	return configList
 }
 
-func parseHeadersResult(config []map[string]interface{}) []interface{} {
+func parseHeadersResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			PathField:       c[PathField].(string),
-			MethodField:     c[MethodField].(string),
-			HeaderNameField: c[HeaderNameField].(string),
-			ValueField:      c[ValueField].(string),
+			PathField:       item.String(PathField),
+			MethodField:     item.String(MethodField),
+			HeaderNameField: item.String(HeaderNameField),
+			ValueField:      item.String(ValueField),
 		})
 	}
 	if len(configList) == 0 {
@@ -167,16 +150,17 @@ func parseHeadersResult(config []map[string]interface{}) []interface{} {
 	return configList
 }
 
-func parseBackendServerResult(config []map[string]interface{}) []interface{} {
+func parseBackendServerResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			ServerTimeoutField:           c[ServerTimeoutField].(string),
-			BackendPortField:             c[BackendPortField].(float64),
-			BackendServersPortCheckField: c[BackendServersPortCheckField].(float64),
-			MaxconnFiled:                 c[MaxconnFiled].(float64),
-			BackendServersSendProxyField: c[BackendServersSendProxyField].(bool),
-			BackendServersBackupField:    c[BackendServersBackupField].(bool),
+			ServerTimeoutField:           item.String(ServerTimeoutField),
+			BackendPortField:             item.Int(BackendPortField),
+			BackendServersPortCheckField: item.Int(BackendServersPortCheckField),
+			MaxconnFiled:                 item.Int(MaxconnFiled),
+			BackendServersSendProxyField: item.Bool(BackendServersSendProxyField),
+			BackendServersBackupField:    item.Bool(BackendServersBackupField),
 		})
 	}
 	if len(configList) == 0 {
@@ -185,13 +169,14 @@ func parseBackendServerResult(config []map[string]interface{}) []interface{} {
 	return configList
 }
 
-func parseUserListConfigListResult(config []map[string]interface{}) []interface{} {
+func parseUserListConfigListResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			UserFiled:      c[UserFiled].(string),
-			PasswordField:  c[PasswordField].(string),
-			GroupNameField: c[GroupNameField].(string),
+			UserFiled:      item.String(UserFiled),
+			PasswordField:  item.String(PasswordField),
+			GroupNameField: item.String(GroupNameField),
 		})
 	}
 	return configList
@@ -216,6 +201,13 @@ func setTimeoutField(d *schema.ResourceData, fieldName string, value interface{}
 		items := make([]interface{}, 0)
 		return d.Set(fieldName, schema.NewSet(hashMapStringInterface, items))
 	}
+	if s, ok := value.(string); ok {
+		parsed, err := api.ParseLiteral(s)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", fieldName, err)
+		}
+		return setTimeoutField(d, fieldName, parsed)
+	}
 	switch v := value.(type) {
 	case map[string]interface{}:
 		items := []interface{}{v}
@@ -226,6 +218,24 @@ func setTimeoutField(d *schema.ResourceData, fieldName string, value interface{}
 	}
 }
 
+// configMapOrNil converts an optional object returned by the API into the
+// value expected by setTimeoutField.
+func configMapOrNil(m api.ConfigMap) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return map[string]interface{}(m)
+}
+
+// expandStringList converts a list of strings from the schema.
+func expandStringList(list []interface{}) api.StringList {
+	strs := make(api.StringList, 0, len(list))
+	for _, item := range list {
+		strs = append(strs, item.(string))
+	}
+	return strs
+}
+
 func getTimeoutMap(d *schema.ResourceData, fieldName string) (map[string]interface{}, error) {
 	v := d.Get(fieldName)
 
@@ -277,13 +287,15 @@ func validateModeAndOptions(d *schema.ResourceDiff) error {
 	return nil
 }
 
-func resourceSectionParseId(fullId string) (string, string, error) {
+func resourceSectionParseId(fullId string) (int, string, error) {
 	parts := strings.Split(fullId, "-")
 	if len(parts) < 2 {
-		return "", "", fmt.Errorf("expected ID in the format 'server_id-section_name', got: %s", fullId)
+		return 0, "", fmt.Errorf("expected ID in the format 'server_id-section_name', got: %s", fullId)
+	}
+	serverID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid server ID %q in section ID %s", parts[0], fullId)
 	}
-	serverId := parts[0]
 	sectionName := strings.Join(parts[1:], "-")
-	return serverId, sectionName, nil
-
+	return serverID, sectionName, nil
 }
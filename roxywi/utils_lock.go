@@ -0,0 +1,80 @@
+package roxywi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+// serverConfigLocks serializes changes to the configuration of one service on
+// one server, so that parallel resources do not overwrite each other's edits
+// of haproxy.cfg or nginx.conf or trigger overlapping reloads.
+var serverConfigLocks = newKeyedMutex()
+
+// keyedMutex is a set of mutexes addressed by key. Waiting for a lock can be
+// cancelled through the context.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: map[string]chan struct{}{}}
+}
+
+func (k *keyedMutex) Lock(ctx context.Context, key string) (func(), error) {
+	k.mu.Lock()
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		k.locks[key] = lock
+	}
+	k.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for lock %s: %w", key, ctx.Err())
+	}
+}
+
+// lockServerConfig blocks until no other resource of this provider is changing
+// the configuration of service on server, and returns the function that
+// releases the lock.
+func lockServerConfig(ctx context.Context, service string, server interface{}) (func(), error) {
+	key := fmt.Sprintf("%s/%v", service, server)
+
+	tflog.Debug(ctx, "Waiting for server configuration lock", map[string]interface{}{"lock": key})
+	unlock, err := serverConfigLocks.Lock(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Acquired server configuration lock", map[string]interface{}{"lock": key})
+
+	return unlock, nil
+}
+
+// lockHaproxyConfigByIP takes the HAProxy configuration lock of the server
+// registered with ip. Resources that address the server by IP must share the
+// lock with the section resources, which address it by ID. An IP that is not
+// registered cannot be the target of a section, so it is locked on its own.
+// If the servers cannot be listed the IP is locked on its own as well: the
+// change itself may still succeed, and the lock must not be the reason it
+// fails.
+func lockHaproxyConfigByIP(ctx context.Context, client *api.Client, ip string) (func(), error) {
+	servers, err := client.ListServers(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "Unable to find the server ID for the HAProxy configuration lock, locking by IP", map[string]interface{}{"ip": ip, "error": err.Error()})
+		return lockServerConfig(ctx, "haproxy", ip)
+	}
+	for i := range servers {
+		if string(servers[i].IP) == ip {
+			return lockServerConfig(ctx, "haproxy", servers[i].Identifier())
+		}
+	}
+	return lockServerConfig(ctx, "haproxy", ip)
+}
@@ -0,0 +1,140 @@
+package roxywi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+// tryLock reports whether key of locks can be taken within a short time,
+// releasing it again if so.
+func tryLock(t *testing.T, locks *keyedMutex, key string) bool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	unlock, err := locks.Lock(ctx, key)
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Lock(%q) error = %v, want a timeout", key, err)
+		}
+		return false
+	}
+	unlock()
+	return true
+}
+
+func TestKeyedMutex(t *testing.T) {
+	locks := newKeyedMutex()
+
+	unlock, err := locks.Lock(context.Background(), "haproxy/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tryLock(t, locks, "haproxy/1") {
+		t.Error("locked haproxy/1 twice, want the second lock to wait for the first")
+	}
+	if !tryLock(t, locks, "haproxy/2") {
+		t.Error("haproxy/2 is blocked by haproxy/1, want other servers to be independent")
+	}
+	if !tryLock(t, locks, "nginx/1") {
+		t.Error("nginx/1 is blocked by haproxy/1, want other services to be independent")
+	}
+
+	unlock()
+	if !tryLock(t, locks, "haproxy/1") {
+		t.Error("haproxy/1 is still locked after unlock")
+	}
+}
+
+func TestKeyedMutexSerializes(t *testing.T) {
+	locks := newKeyedMutex()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running = map[string]int{}
+		maxRun  = map[string]int{}
+	)
+	for i := 0; i < 20; i++ {
+		key := []string{"haproxy/1", "haproxy/2"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := locks.Lock(context.Background(), key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+
+			mu.Lock()
+			running[key]++
+			if running[key] > maxRun[key] {
+				maxRun[key] = running[key]
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running[key]--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for key, n := range maxRun {
+		if n != 1 {
+			t.Errorf("%d operations held %s at once, want 1", n, key)
+		}
+	}
+}
+
+// doerFunc adapts a function to api.Doer.
+type doerFunc func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error)
+
+func (f doerFunc) DoRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	return f(ctx, method, endpoint, body)
+}
+
+func TestLockHaproxyConfigByIP(t *testing.T) {
+	servers := api.New(doerFunc(func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+		return []byte(`[{"server_id": 7, "ip": "10.0.0.7"}]`), nil
+	}))
+	failing := api.New(doerFunc(func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+		return nil, newHTTPError(method, endpoint, http.StatusInternalServerError, nil)
+	}))
+
+	tests := []struct {
+		name   string
+		client *api.Client
+		ip     string
+		key    string
+	}{
+		{name: "registered server", client: servers, ip: "10.0.0.7", key: "haproxy/7"},
+		{name: "unknown IP", client: servers, ip: "10.0.0.8", key: "haproxy/10.0.0.8"},
+		{name: "listing fails", client: failing, ip: "10.0.0.7", key: "haproxy/10.0.0.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unlock, err := lockHaproxyConfigByIP(context.Background(), tt.client, tt.ip)
+			if err != nil {
+				t.Fatalf("lockHaproxyConfigByIP(%q) returned error: %v", tt.ip, err)
+			}
+			defer unlock()
+
+			if tryLock(t, serverConfigLocks, tt.key) {
+				t.Errorf("lockHaproxyConfigByIP(%q) did not take %s", tt.ip, tt.key)
+			}
+		})
+	}
+}
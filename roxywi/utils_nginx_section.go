@@ -14,14 +14,15 @@ func parseNginxBackendsServerList(configList []interface{}) []map[string]interfa
 	return configs
 }
 
-func parseNginxBackendServerResult(config []map[string]interface{}) []interface{} {
+func parseNginxBackendServerResult(r *responseDecoder, field string, config []map[string]interface{}) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			ServerTimeoutField: c[ServerTimeoutField].(string),
-			BackendPortField:   c[BackendPortField].(float64),
-			MaxFails:           c[MaxFails].(float64),
-			FailTimeout:        c[FailTimeout].(float64),
+			ServerTimeoutField: item.String(ServerTimeoutField),
+			BackendPortField:   item.Int(BackendPortField),
+			MaxFails:           item.Int(MaxFails),
+			FailTimeout:        item.Int(FailTimeout),
 		})
 	}
 	if len(configList) == 0 {
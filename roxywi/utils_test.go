@@ -0,0 +1,27 @@
+package roxywi
+
+import "testing"
+
+func TestValidateStoredText(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "Bob's edge LB"},
+		{value: `Edge "LB" <prod>`},
+		{value: "R&D", wantErr: true},
+		{value: "a;b", wantErr: true},
+		{value: "a|b", wantErr: true},
+		{value: "$HOME", wantErr: true},
+		{value: "`id`", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, errs := validateStoredText(tt.value, DescriptionField)
+			if got := len(errs) > 0; got != tt.wantErr {
+				t.Errorf("validateStoredText(%q) error = %v, want %v", tt.value, got, tt.wantErr)
+			}
+		})
+	}
+}
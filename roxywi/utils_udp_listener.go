@@ -1,10 +1,11 @@
 package roxywi
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
-	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-roxywi/roxywi/api"
 )
 
 func parseConfigList(configList []interface{}) []map[string]interface{} {
@@ -20,37 +21,14 @@ func parseConfigList(configList []interface{}) []map[string]interface{} {
 	return configs
 }
 
-func parseConfig(config interface{}) ([]map[string]interface{}, error) {
-	if config == nil {
-		return nil, nil
-	}
-	switch v := config.(type) {
-	case string:
-		var parsedConfig []map[string]interface{}
-		log.Printf("[DEBUG] Config string before parsing: %s", v)
-		v = strings.ReplaceAll(v, "'", "\"")
-		if err := json.Unmarshal([]byte(v), &parsedConfig); err != nil {
-			return nil, fmt.Errorf("failed to parse config field: %v", err)
-		}
-		return parsedConfig, nil
-	case []interface{}:
-		var parsedConfig []map[string]interface{}
-		for _, item := range v {
-			parsedConfig = append(parsedConfig, item.(map[string]interface{}))
-		}
-		return parsedConfig, nil
-	default:
-		return nil, fmt.Errorf("invalid config format: %v", config)
-	}
-}
-
-func parseConfigResult(config []map[string]interface{}) []interface{} {
+func parseConfigResult(r *responseDecoder, field string, config api.ConfigList) []interface{} {
 	var configList []interface{}
-	for _, c := range config {
+	for i, c := range config {
+		item := r.Item(field, i, c)
 		configList = append(configList, map[string]interface{}{
-			BackendIPField:     c[BackendIPField].(string),
-			BackendPortField:   intFromInterface(c[BackendPortField]),
-			BackendWeightField: intFromInterface(c[BackendWeightField]),
+			BackendIPField:     item.String(BackendIPField),
+			BackendPortField:   item.Int(BackendPortField),
+			BackendWeightField: item.Int(BackendWeightField),
 		})
 	}
 	return configList
@@ -71,46 +49,32 @@ func intFromInterface(value interface{}) int {
 	}
 }
 
-func checkVipExists(client *Client, clusterID, serverID int, vip string) error {
-	var url string
-	if clusterID != 0 {
-		url = fmt.Sprintf("/api/ha/cluster/%d/vips", clusterID)
-	} else if serverID != 0 {
-		url = fmt.Sprintf("/api/server/%d/ip", serverID)
-	} else {
+func checkVipExists(ctx context.Context, client *api.Client, clusterID, serverID int, vip string) error {
+	if clusterID == 0 && serverID == 0 {
 		return fmt.Errorf("either cluster_id or server_id must be specified")
 	}
 
-	log.Printf("[DEBUG] Checking VIP existence with URL: %s", url)
-
-	resp, err := client.doRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to do request: %v", err)
-	}
-
-	log.Printf("[DEBUG] Response from VIP check: %s", string(resp))
-
 	if clusterID != 0 {
-		var result []map[string]interface{}
-		if err := json.Unmarshal(resp, &result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %v", err)
+		vips, err := client.ListHaClusterVips(ctx, clusterID)
+		if err != nil && !api.IsDecodeError(err) {
+			return fmt.Errorf("failed to do request: %v", err)
 		}
 
-		for _, item := range result {
-			if itemVip, ok := item["vip"].(string); ok && itemVip == vip {
+		for _, item := range vips {
+			if string(item.VIP) == vip {
 				return nil
 			}
 		}
 	} else {
-		var ips []string
-		if err := json.Unmarshal(resp, &ips); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %v", err)
+		ips, err := client.GetServerIPs(ctx, serverID)
+		if err != nil {
+			return fmt.Errorf("failed to do request: %v", err)
 		}
 
-		log.Printf("[DEBUG] Parsed response: %v", ips)
+		tflog.Debug(ctx, "Server IP addresses", map[string]interface{}{"server_id": serverID, "ips": ips})
 
-		for _, itemVip := range ips {
-			if itemVip == vip {
+		for _, ip := range ips {
+			if ip == vip {
 				return nil
 			}
 		}
@@ -0,0 +1,114 @@
+package roxywi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"terraform-provider-roxywi/roxywi/api"
+)
+
+// Version is a Roxy-WI release. The zero value means the version could not
+// be detected, in which case every feature is assumed to be available.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// letsencryptMinVersion is the first Roxy-WI release with Let's Encrypt
+// certificates, including the DNS-01 challenge through Cloudflare,
+// DigitalOcean, Linode and Route53, according to the release notes of
+// github.com/roxy-wi/roxy-wi. It is the only feature gated on the version:
+// every other resource uses the same endpoints and payloads on all releases
+// the provider supports.
+var letsencryptMinVersion = Version{8, 1, 0}
+
+// parseVersion parses versions such as "8.1.2", "v8.1" or "8.1.2-beta".
+func parseVersion(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid Roxy-WI version %q", s)
+		}
+		numbers[i] = n
+	}
+	return Version{numbers[0], numbers[1], numbers[2]}, nil
+}
+
+func (v Version) IsKnown() bool {
+	return v != Version{}
+}
+
+// AtLeast reports whether v is min or newer. Unknown versions satisfy every
+// minimum.
+func (v Version) AtLeast(min Version) bool {
+	if !v.IsKnown() {
+		return true
+	}
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	if v.Minor != min.Minor {
+		return v.Minor > min.Minor
+	}
+	return v.Patch >= min.Patch
+}
+
+func (v Version) String() string {
+	if !v.IsKnown() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// detectVersion asks Roxy-WI for its version with GET /api/version, see
+// api.Client.GetVersion. Failures are logged and leave the version unknown,
+// so that releases without that endpoint keep working with every feature
+// enabled rather than being refused.
+func detectVersion(ctx context.Context, client *api.Client) Version {
+	raw, err := client.GetVersion(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "Unable to detect Roxy-WI version, assuming all features are available", map[string]interface{}{"error": err.Error()})
+		return Version{}
+	}
+
+	version, err := parseVersion(raw)
+	if err != nil {
+		tflog.Warn(ctx, "Unable to parse Roxy-WI version, assuming all features are available", map[string]interface{}{"error": err.Error()})
+		return Version{}
+	}
+
+	tflog.Info(ctx, "Detected Roxy-WI version", map[string]interface{}{"version": version.String()})
+	return version
+}
+
+// requireVersion returns an error if the Roxy-WI the provider is connected to
+// is older than min. feature names what is unavailable in the message.
+func requireVersion(m interface{}, feature string, min Version) error {
+	config, ok := m.(*Config)
+	if !ok || config.Version.AtLeast(min) {
+		return nil
+	}
+	return fmt.Errorf("%s requires Roxy-WI >= %s, but the server runs %s", feature, min, config.Version)
+}
+
+// requireVersionDiff fails plans of resources that need a Roxy-WI release
+// newer than the one the provider is connected to.
+func requireVersionDiff(feature string, min Version) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		return requireVersion(m, feature, min)
+	}
+}